@@ -1,89 +1,499 @@
 #define BUILDING_BRIDGE
 #include "bridge.h"
 #include <ortools/linear_solver/linear_solver.h>
+#include <ortools/linear_solver/linear_solver.pb.h>
+#include <algorithm>
+#include <cmath>
+#include <exception>
+#include <string>
+#include <utility>
+#include <vector>
 
 // This is a C interface to the OR-Tools linear solver. It is a simple wrapper around the C++ API
-// without syntax sugar or error handling. The goal is to provide a minimalistic interface that can
-// be used in Go with CGO
+// without syntax sugar. The goal is to provide a minimalistic interface that can be used in Go with CGO.
+// Every function runs its body through guard, so that invalid arguments and C++ exceptions are
+// reported with BRIDGE_ERROR and LastError instead of aborting the process.
 
 namespace {
     using Solver = operations_research::MPSolver;
     using Variable = operations_research::MPVariable;
     using Constraint = operations_research::MPConstraint;
+    using SolverParameters = operations_research::MPSolverParameters;
+    using GeneralConstraintProto = operations_research::MPGeneralConstraintProto;
+
+    // A CSolver points to a SolverHandle: the solver together with the parameters used to solve it.
+    struct SolverHandle {
+        Solver *solver;
+        SolverParameters params;
+        // general constraints waiting to be loaded by LoadGeneralConstraints
+        std::vector<GeneralConstraintProto> pending = {};
+    };
+
+    Solver *solverOf(CSolver *solver) {
+        return reinterpret_cast<SolverHandle *>(solver)->solver;
+    }
+
+    thread_local std::string last_error;
+
+    // fail records the message of a failed call and returns BRIDGE_ERROR.
+    int fail(std::string message) {
+        last_error = std::move(message);
+        return BRIDGE_ERROR;
+    }
+
+    // guard runs the body of a bridge function, which returns BRIDGE_OK or fail(...), catching any exception.
+    template <typename F>
+    int guard(F &&body) {
+        try {
+            return body();
+        } catch (const std::exception &e) {
+            return fail(std::string("C++ exception: ") + e.what());
+        } catch (...) {
+            return fail("unknown C++ exception");
+        }
+    }
+
+    std::string toString(const char *s, int len) {
+        return len > 0 ? std::string(s, len) : std::string();
+    }
+
+    void copyString(const std::string &s, char *buf, int buf_len, int *len) {
+        if (buf != nullptr && buf_len > 0) {
+            s.copy(buf, std::min<size_t>(s.size(), buf_len));
+        }
+        *len = static_cast<int>(s.size());
+    }
+
+    // checkBounds fails on NaN bounds and on empty intervals, which MPSolver silently accepts.
+    int checkBounds(double lb, double ub) {
+        if (std::isnan(lb) || std::isnan(ub)) {
+            return fail("bounds must not be NaN");
+        }
+        if (lb > ub) {
+            return fail("lower bound " + std::to_string(lb) + " is greater than upper bound " + std::to_string(ub));
+        }
+        return BRIDGE_OK;
+    }
 }
 
 extern "C" {
-CSolver *CreateSolver(const char *solver_type) {
-    Solver *solver = Solver::CreateSolver(solver_type);
-    return reinterpret_cast<CSolver *>(solver);
+int LastError(char *buf, int buf_len, int *len) {
+    copyString(last_error, buf, buf_len, len);
+    return BRIDGE_OK;
+}
+
+int SolverTypeAvailable(const char *solver_type, int *available) {
+    return guard([&] {
+        Solver::OptimizationProblemType type;
+        *available = Solver::ParseSolverType(solver_type, &type) && Solver::SupportsProblemType(type) ? 1 : 0;
+        return BRIDGE_OK;
+    });
+}
+
+int CreateSolver(const char *solver_type, CSolver **solver) {
+    return guard([&] {
+        Solver *s = Solver::CreateSolver(solver_type);
+        if (s == nullptr) {
+            return fail(std::string("solver type ") + solver_type + " is not available in this OR-Tools build");
+        }
+        *solver = reinterpret_cast<CSolver *>(new SolverHandle{s, SolverParameters()});
+        return BRIDGE_OK;
+    });
+}
+
+int DeleteSolver(CSolver *solver) {
+    return guard([&] {
+        auto *handle = reinterpret_cast<SolverHandle *>(solver);
+        delete handle->solver;
+        delete handle;
+        return BRIDGE_OK;
+    });
+}
+
+int AddVar(CSolver *solver, const char *name, int name_len, double lb, double ub, int is_integer, CVariable **var) {
+    return guard([&] {
+        if (checkBounds(lb, ub) != BRIDGE_OK) {
+            return BRIDGE_ERROR;
+        }
+        auto *s = solverOf(solver);
+        Variable *v = s->MakeVar(lb, ub, is_integer, toString(name, name_len));
+        *var = reinterpret_cast<CVariable *>(v);
+        return BRIDGE_OK;
+    });
+}
+
+int VariableName(CVariable *var, char *buf, int buf_len, int *len) {
+    return guard([&] {
+        auto *v = reinterpret_cast<Variable *>(var);
+        copyString(v->name(), buf, buf_len, len);
+        return BRIDGE_OK;
+    });
+}
+
+int AddConstraint(CSolver *solver, const char *name, int name_len, double lb, double ub, CConstraint **constraint) {
+    return guard([&] {
+        if (checkBounds(lb, ub) != BRIDGE_OK) {
+            return BRIDGE_ERROR;
+        }
+        auto *s = solverOf(solver);
+        auto *c = s->MakeRowConstraint(lb, ub, toString(name, name_len));
+        *constraint = reinterpret_cast<CConstraint *>(c);
+        return BRIDGE_OK;
+    });
+}
+
+int ConstraintName(CConstraint *constraint, char *buf, int buf_len, int *len) {
+    return guard([&] {
+        auto *c = reinterpret_cast<Constraint *>(constraint);
+        copyString(c->name(), buf, buf_len, len);
+        return BRIDGE_OK;
+    });
+}
+
+int SetConstraintBounds(CConstraint *constraint, double lb, double ub) {
+    return guard([&] {
+        if (checkBounds(lb, ub) != BRIDGE_OK) {
+            return BRIDGE_ERROR;
+        }
+        auto *c = reinterpret_cast<Constraint *>(constraint);
+        c->SetBounds(lb, ub);
+        return BRIDGE_OK;
+    });
+}
+
+int SetCoefficient(CConstraint *constraint, CVariable *var, double coeff) {
+    return guard([&] {
+        if (!std::isfinite(coeff)) {
+            return fail("coefficients must be finite");
+        }
+        auto *c = reinterpret_cast<Constraint *>(constraint);
+        auto *v = reinterpret_cast<Variable *>(var);
+        c->SetCoefficient(v, coeff);
+        return BRIDGE_OK;
+    });
+}
+
+int AddIndicatorConstraint(CSolver *solver, CVariable *indicator, int indicator_value, double lb, double ub,
+                           CVariable **vars, double *coeffs, int n) {
+    return guard([&] {
+        if (checkBounds(lb, ub) != BRIDGE_OK) {
+            return BRIDGE_ERROR;
+        }
+        GeneralConstraintProto general;
+        auto *indicator_constraint = general.mutable_indicator_constraint();
+        indicator_constraint->set_var_index(reinterpret_cast<Variable *>(indicator)->index());
+        indicator_constraint->set_var_value(indicator_value);
+        auto *row = indicator_constraint->mutable_constraint();
+        row->set_lower_bound(lb);
+        row->set_upper_bound(ub);
+        for (int i = 0; i < n; ++i) {
+            if (!std::isfinite(coeffs[i])) {
+                return fail("coefficients must be finite");
+            }
+            row->add_var_index(reinterpret_cast<Variable *>(vars[i])->index());
+            row->add_coefficient(coeffs[i]);
+        }
+        reinterpret_cast<SolverHandle *>(solver)->pending.push_back(std::move(general));
+        return BRIDGE_OK;
+    });
+}
+
+// MPSolver can only create general constraints when loading a model: the model is exported with the pending
+// constraints added, and loaded back, which replaces every variable and constraint of the solver.
+int LoadGeneralConstraints(CSolver *solver) {
+    return guard([&] {
+        auto *handle = reinterpret_cast<SolverHandle *>(solver);
+        if (handle->pending.empty()) {
+            return BRIDGE_OK;
+        }
+        operations_research::MPModelProto model;
+        handle->solver->ExportModelToProto(&model);
+        for (auto &general : handle->pending) {
+            *model.add_general_constraint() = std::move(general);
+        }
+        handle->pending.clear();
+
+        std::string error;
+        if (handle->solver->LoadModelFromProto(model, &error, /*clear_names=*/false) !=
+            operations_research::MPSOLVER_MODEL_IS_VALID) {
+            return fail("loading general constraints: " + error);
+        }
+        return BRIDGE_OK;
+    });
+}
+
+int SolverVariable(CSolver *solver, int index, CVariable **var) {
+    return guard([&] {
+        auto *s = solverOf(solver);
+        if (index < 0 || index >= s->NumVariables()) {
+            return fail("no variable " + std::to_string(index));
+        }
+        *var = reinterpret_cast<CVariable *>(s->variable(index));
+        return BRIDGE_OK;
+    });
+}
+
+int SolverConstraint(CSolver *solver, int index, CConstraint **constraint) {
+    return guard([&] {
+        auto *s = solverOf(solver);
+        if (index < 0 || index >= s->NumConstraints()) {
+            return fail("no constraint " + std::to_string(index));
+        }
+        *constraint = reinterpret_cast<CConstraint *>(s->constraint(index));
+        return BRIDGE_OK;
+    });
+}
+
+int SetObjectiveCoefficient(CSolver *solver, CVariable *var, double coeff) {
+    return guard([&] {
+        if (!std::isfinite(coeff)) {
+            return fail("objective coefficients must be finite");
+        }
+        auto *s = solverOf(solver);
+        auto *v = reinterpret_cast<Variable *>(var);
+        s->MutableObjective()->SetCoefficient(v, coeff);
+        return BRIDGE_OK;
+    });
+}
+
+int SetObjectiveOffset(CSolver *solver, double offset) {
+    return guard([&] {
+        if (!std::isfinite(offset)) {
+            return fail("the objective offset must be finite");
+        }
+        auto *s = solverOf(solver);
+        s->MutableObjective()->SetOffset(offset);
+        return BRIDGE_OK;
+    });
+}
+
+int SetMaximization(CSolver *solver) {
+    return guard([&] {
+        auto *s = solverOf(solver);
+        s->MutableObjective()->SetMaximization();
+        return BRIDGE_OK;
+    });
+}
+
+int SetMinimization(CSolver *solver) {
+    return guard([&] {
+        auto *s = solverOf(solver);
+        s->MutableObjective()->SetMinimization();
+        return BRIDGE_OK;
+    });
+}
+
+int SetTimeLimit(CSolver *solver, int time_limit_milliseconds) {
+    return guard([&] {
+        auto *s = solverOf(solver);
+        // a non-positive time limit removes any previously set limit
+        const absl::Duration time_limit = time_limit_milliseconds > 0
+            ? absl::Milliseconds(time_limit_milliseconds)
+            : absl::InfiniteDuration();
+        s->SetTimeLimit(time_limit);
+        return BRIDGE_OK;
+    });
+}
+
+int Solve(CSolver *solver, int *result_status) {
+    return guard([&] {
+        auto *handle = reinterpret_cast<SolverHandle *>(solver);
+        *result_status = handle->solver->Solve(handle->params);
+        return BRIDGE_OK;
+    });
+}
+
+int SetRelativeMipGap(CSolver *solver, double gap) {
+    return guard([&] {
+        auto &params = reinterpret_cast<SolverHandle *>(solver)->params;
+        if (gap > 0) {
+            params.SetDoubleParam(SolverParameters::RELATIVE_MIP_GAP, gap);
+        } else {
+            params.ResetDoubleParam(SolverParameters::RELATIVE_MIP_GAP);
+        }
+        return BRIDGE_OK;
+    });
+}
+
+int SetPresolve(CSolver *solver, int presolve) {
+    return guard([&] {
+        auto &params = reinterpret_cast<SolverHandle *>(solver)->params;
+        if (presolve >= 0) {
+            params.SetIntegerParam(SolverParameters::PRESOLVE,
+                                   presolve ? SolverParameters::PRESOLVE_ON : SolverParameters::PRESOLVE_OFF);
+        } else {
+            params.ResetIntegerParam(SolverParameters::PRESOLVE);
+        }
+        return BRIDGE_OK;
+    });
+}
+
+int SetLpAlgorithm(CSolver *solver, int algorithm) {
+    return guard([&] {
+        auto &params = reinterpret_cast<SolverHandle *>(solver)->params;
+        if (algorithm > 0) {
+            params.SetIntegerParam(SolverParameters::LP_ALGORITHM, algorithm);
+        } else {
+            params.ResetIntegerParam(SolverParameters::LP_ALGORITHM);
+        }
+        return BRIDGE_OK;
+    });
+}
+
+int SetNumThreads(CSolver *solver, int num_threads) {
+    return guard([&] {
+        auto *s = solverOf(solver);
+        const absl::Status status = s->SetNumThreads(num_threads);
+        if (!status.ok()) {
+            return fail(std::string(status.message()));
+        }
+        return BRIDGE_OK;
+    });
+}
+
+int EnableOutput(CSolver *solver, int enable) {
+    return guard([&] {
+        auto *s = solverOf(solver);
+        if (enable) {
+            s->EnableOutput();
+        } else {
+            s->SuppressOutput();
+        }
+        return BRIDGE_OK;
+    });
+}
+
+int SetSolverSpecificParameters(CSolver *solver, const char *parameters) {
+    return guard([&] {
+        auto *s = solverOf(solver);
+        if (!s->SetSolverSpecificParametersAsString(parameters)) {
+            return fail("the solver rejected the solver specific parameters");
+        }
+        return BRIDGE_OK;
+    });
+}
+
+// InterruptSolve may be called from another thread while Solve is running.
+// supported is set to 1 if the underlying solver supports interruption, 0 otherwise.
+int InterruptSolve(CSolver *solver, int *supported) {
+    return guard([&] {
+        auto *s = solverOf(solver);
+        *supported = s->InterruptSolve() ? 1 : 0;
+        return BRIDGE_OK;
+    });
 }
 
-void DeleteSolver(CSolver *solver) {
-    delete reinterpret_cast<Solver *>(solver);
+int SetHint(CSolver *solver, CVariable **vars, double *values, int n) {
+    return guard([&] {
+        auto *s = solverOf(solver);
+        std::vector<std::pair<const Variable *, double>> hint;
+        hint.reserve(n);
+        for (int i = 0; i < n; ++i) {
+            hint.emplace_back(reinterpret_cast<Variable *>(vars[i]), values[i]);
+        }
+        s->SetHint(hint);
+        return BRIDGE_OK;
+    });
 }
 
-CVariable *AddVar(CSolver *solver, const char *name, double lb, double ub, int is_integer) {
-    auto *s = reinterpret_cast<Solver *>(solver);
-    Variable *var = s->MakeVar(lb, ub, is_integer, name);
-    return reinterpret_cast<CVariable *>(var);
+int ObjectiveValue(CSolver *solver, double *value) {
+    return guard([&] {
+        auto *s = solverOf(solver);
+        *value = s->Objective().Value();
+        return BRIDGE_OK;
+    });
 }
 
-const char* VariableName(void* variable) {
-    Variable* var = (Variable*)variable;
-    return var->name().c_str();
+int SolutionValue(CVariable *var, double *value) {
+    return guard([&] {
+        auto *v = reinterpret_cast<Variable *>(var);
+        *value = v->solution_value();
+        return BRIDGE_OK;
+    });
 }
 
-CConstraint *AddConstraint(CSolver *solver, double lb, double ub) {
-    auto *s = reinterpret_cast<Solver *>(solver);
-    auto *constraint = s->MakeRowConstraint(lb, ub);
-    return reinterpret_cast<CConstraint *>(constraint);
+// ReducedCost and VariableBasisStatus are only available when IsMip returns 0
+int ReducedCost(CVariable *var, double *value) {
+    return guard([&] {
+        auto *v = reinterpret_cast<Variable *>(var);
+        *value = v->reduced_cost();
+        return BRIDGE_OK;
+    });
 }
 
-void SetCoefficient(CConstraint *constraint, CVariable *var, double coeff) {
-    auto *c = reinterpret_cast<Constraint *>(constraint);
-    auto *v = reinterpret_cast<Variable *>(var);
-    c->SetCoefficient(v, coeff);
+int VariableBasisStatus(CVariable *var, int *status) {
+    return guard([&] {
+        auto *v = reinterpret_cast<Variable *>(var);
+        *status = static_cast<int>(v->basis_status());
+        return BRIDGE_OK;
+    });
 }
 
-void SetObjectiveCoefficient(CSolver *solver, CVariable *var, double coeff) {
-    auto *s = reinterpret_cast<Solver *>(solver);
-    auto *v = reinterpret_cast<Variable *>(var);
-    s->MutableObjective()->SetCoefficient(v, coeff);
+int SetVariableBounds(CVariable *var, double lb, double ub) {
+    return guard([&] {
+        if (checkBounds(lb, ub) != BRIDGE_OK) {
+            return BRIDGE_ERROR;
+        }
+        auto *v = reinterpret_cast<Variable *>(var);
+        v->SetBounds(lb, ub);
+        return BRIDGE_OK;
+    });
 }
 
-void SetMaximization(CSolver *solver) {
-    auto *s = reinterpret_cast<Solver *>(solver);
-    s->MutableObjective()->SetMaximization();
+int SetVariableInteger(CVariable *var, int is_integer) {
+    return guard([&] {
+        auto *v = reinterpret_cast<Variable *>(var);
+        v->SetInteger(is_integer != 0);
+        return BRIDGE_OK;
+    });
 }
 
-void SetMinimization(CSolver *solver) {
-    auto *s = reinterpret_cast<Solver *>(solver);
-    s->MutableObjective()->SetMinimization();
+int GetBestBound(CSolver *solver, double *value) {
+    return guard([&] {
+        auto *s = solverOf(solver);
+        *value = s->Objective().BestBound();
+        return BRIDGE_OK;
+    });
 }
 
-void SetTimeLimit(CSolver *solver, int time_limit_milliseconds) {
-    auto *s = reinterpret_cast<Solver *>(solver);
-    const absl::Duration time_limit = absl::Milliseconds(time_limit_milliseconds);
-    s->SetTimeLimit(time_limit);
+int IsMip(CSolver *solver, int *is_mip) {
+    return guard([&] {
+        auto *s = solverOf(solver);
+        *is_mip = s->IsMIP() ? 1 : 0;
+        return BRIDGE_OK;
+    });
 }
 
-int Solve(CSolver *solver) {
-    auto *s = reinterpret_cast<Solver *>(solver);
-    return s->Solve();
+// DualValue and ConstraintBasisStatus are only available when IsMip returns 0
+int DualValue(CConstraint *constraint, double *value) {
+    return guard([&] {
+        auto *c = reinterpret_cast<Constraint *>(constraint);
+        *value = c->dual_value();
+        return BRIDGE_OK;
+    });
 }
 
-double ObjectiveValue(CSolver *solver) {
-    auto *s = reinterpret_cast<Solver *>(solver);
-    return s->Objective().Value();
+int ConstraintBasisStatus(CConstraint *constraint, int *status) {
+    return guard([&] {
+        auto *c = reinterpret_cast<Constraint *>(constraint);
+        *status = static_cast<int>(c->basis_status());
+        return BRIDGE_OK;
+    });
 }
 
-double SolutionValue(CVariable *var) {
-    auto *v = reinterpret_cast<Variable *>(var);
-    return v->solution_value();
+int Iterations(CSolver *solver, long long *iterations) {
+    return guard([&] {
+        auto *s = solverOf(solver);
+        *iterations = s->iterations();
+        return BRIDGE_OK;
+    });
 }
 
-double GetBestBound(CSolver *solver) {
-    auto *s = reinterpret_cast<Solver *>(solver);
-    return s->Objective().BestBound();
+int Nodes(CSolver *solver, long long *nodes) {
+    return guard([&] {
+        auto *s = solverOf(solver);
+        *nodes = s->nodes();
+        return BRIDGE_OK;
+    });
 }
 }
@@ -19,20 +19,66 @@ typedef void* CSolver;
 typedef void* CVariable;
 typedef void* CConstraint;
 
-BRIDGE_API CSolver *CreateSolver(const char *solver_type);
-BRIDGE_API void DeleteSolver(CSolver* solver);
-BRIDGE_API CVariable* AddVar(CSolver* solver, const char* name, double lb, double ub, int is_integer);
-BRIDGE_API CConstraint* AddConstraint(CSolver* solver, double lb, double ub);
-BRIDGE_API void SetCoefficient(CConstraint* constraint, CVariable* var, double coeff);
-BRIDGE_API void SetObjectiveCoefficient(CSolver* solver, CVariable* var, double coeff);
-BRIDGE_API const char* VariableName(void* variable);
-BRIDGE_API void SetMaximization(CSolver* solver);
-BRIDGE_API void SetMinimization(CSolver* solver);
-BRIDGE_API void SetTimeLimit(CSolver *solver, int time_limit_milliseconds);
-BRIDGE_API int Solve(CSolver* solver);
-BRIDGE_API double ObjectiveValue(CSolver* solver);
-BRIDGE_API double SolutionValue(CVariable* var);
-BRIDGE_API double GetBestBound(CSolver *solver);
+// Every function returns BRIDGE_OK or BRIDGE_ERROR, results are written to the out parameters.
+// On BRIDGE_ERROR, nothing is written to the out parameters and LastError returns the message of the
+// failure, which is kept per thread: it must be read on the thread of the failed call.
+// C++ exceptions never cross the bridge, they are reported as errors.
+#define BRIDGE_OK 0
+#define BRIDGE_ERROR (-1)
+
+// LastError copies at most buf_len bytes of the last error message of the calling thread into buf,
+// without a terminating NUL, and writes the length of the message to len.
+BRIDGE_API int LastError(char* buf, int buf_len, int* len);
+
+// SolverTypeAvailable sets available to 1 if solver_type is a solver type name known to OR-Tools
+// and the solver is linked into the library, 0 otherwise.
+BRIDGE_API int SolverTypeAvailable(const char* solver_type, int* available);
+BRIDGE_API int CreateSolver(const char* solver_type, CSolver** solver);
+BRIDGE_API int DeleteSolver(CSolver* solver);
+// Names are passed as (pointer, length) and copied, they may contain any byte including NUL.
+BRIDGE_API int AddVar(CSolver* solver, const char* name, int name_len, double lb, double ub, int is_integer, CVariable** var);
+BRIDGE_API int AddConstraint(CSolver* solver, const char* name, int name_len, double lb, double ub, CConstraint** constraint);
+BRIDGE_API int SetConstraintBounds(CConstraint* constraint, double lb, double ub);
+BRIDGE_API int SetCoefficient(CConstraint* constraint, CVariable* var, double coeff);
+// AddIndicatorConstraint records the row lb <= sum(coeffs[i] * vars[i]) <= ub, enforced when the binary variable
+// indicator equals indicator_value. It is passed to the solver by LoadGeneralConstraints.
+BRIDGE_API int AddIndicatorConstraint(CSolver* solver, CVariable* indicator, int indicator_value, double lb, double ub, CVariable** vars, double* coeffs, int n);
+// LoadGeneralConstraints passes the recorded general constraints to the solver, which reloads its whole model:
+// all the CVariable and CConstraint handles of the solver become invalid, SolverVariable and SolverConstraint
+// return the new ones. The linear constraints come first, in their order, followed by the general constraints.
+BRIDGE_API int LoadGeneralConstraints(CSolver* solver);
+BRIDGE_API int SolverVariable(CSolver* solver, int index, CVariable** var);
+BRIDGE_API int SolverConstraint(CSolver* solver, int index, CConstraint** constraint);
+BRIDGE_API int SetObjectiveCoefficient(CSolver* solver, CVariable* var, double coeff);
+BRIDGE_API int SetObjectiveOffset(CSolver* solver, double offset);
+// VariableName and ConstraintName copy at most buf_len bytes of the name into buf, without a terminating NUL,
+// and write the length of the name to len.
+BRIDGE_API int VariableName(CVariable* var, char* buf, int buf_len, int* len);
+BRIDGE_API int ConstraintName(CConstraint* constraint, char* buf, int buf_len, int* len);
+BRIDGE_API int SetMaximization(CSolver* solver);
+BRIDGE_API int SetMinimization(CSolver* solver);
+BRIDGE_API int SetTimeLimit(CSolver *solver, int time_limit_milliseconds);
+BRIDGE_API int Solve(CSolver* solver, int* result_status);
+BRIDGE_API int InterruptSolve(CSolver* solver, int* supported);
+BRIDGE_API int SetRelativeMipGap(CSolver* solver, double gap);
+BRIDGE_API int SetPresolve(CSolver* solver, int presolve);
+BRIDGE_API int SetLpAlgorithm(CSolver* solver, int algorithm);
+BRIDGE_API int SetNumThreads(CSolver* solver, int num_threads);
+BRIDGE_API int EnableOutput(CSolver* solver, int enable);
+BRIDGE_API int SetSolverSpecificParameters(CSolver* solver, const char* parameters);
+BRIDGE_API int SetHint(CSolver* solver, CVariable** vars, double* values, int n);
+BRIDGE_API int ObjectiveValue(CSolver* solver, double* value);
+BRIDGE_API int SolutionValue(CVariable* var, double* value);
+BRIDGE_API int ReducedCost(CVariable* var, double* value);
+BRIDGE_API int VariableBasisStatus(CVariable* var, int* status);
+BRIDGE_API int SetVariableBounds(CVariable* var, double lb, double ub);
+BRIDGE_API int SetVariableInteger(CVariable* var, int is_integer);
+BRIDGE_API int GetBestBound(CSolver *solver, double* value);
+BRIDGE_API int IsMip(CSolver *solver, int* is_mip);
+BRIDGE_API int DualValue(CConstraint *constraint, double* value);
+BRIDGE_API int ConstraintBasisStatus(CConstraint *constraint, int* status);
+BRIDGE_API int Iterations(CSolver *solver, long long* iterations);
+BRIDGE_API int Nodes(CSolver *solver, long long* nodes);
 
 #ifdef __cplusplus
 }
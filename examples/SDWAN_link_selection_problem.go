@@ -75,7 +75,7 @@ func RoutingCtrlLinkSelection(timeLimit time.Duration) {
 		selections[group] = make([]*mip.Variable, numLinks)
 		for link := range selections[group] {
 			varName := fmt.Sprintf("link_%d_selected_by_group_%d", link, group)
-			selections[group][link] = solver.VarBool(fmt.Sprintf(varName, group, link))
+			selections[group][link] = solver.VarBool(varName)
 		}
 	}
 
@@ -110,11 +110,10 @@ func RoutingCtrlLinkSelection(timeLimit time.Duration) {
 		}
 
 		// Constraint: (used capacity)/(total capacity) <= (device usage upperbound)
-		// i.e. {used capacity} - {total capacity} * {device usage upperbound} <= 0:
-		leftHandSide := mip.NewLinearExpression()             // 0
-		leftHandSide.AddExpr(usedCapacity)                    // +{used capacity}
-		leftHandSide.AddTerm(usageUB[device], -totalCapacity) // -{total capacity} * {device usage upperbound}
-		solver.AddConstraintExpr(leftHandSide, mip.LessThanOrEqual, 0)
+		// i.e. {used capacity} <= {total capacity} * {device usage upperbound}:
+		availableCapacity := mip.NewLinearExpression()
+		availableCapacity.AddTerm(usageUB[device], totalCapacity)
+		solver.AddConstraint(usedCapacity, mip.LessThanOrEqual, availableCapacity)
 	}
 
 	// Variable: Global usage upperbound, to be used in the objective function
@@ -123,11 +122,11 @@ func RoutingCtrlLinkSelection(timeLimit time.Duration) {
 
 	for _, deviceUsageUB := range usageUB {
 		// local device usage upperbound <= global usage upperbound
-		// (globalUB - deviceUB) <= 0
-		ubDiff := mip.NewLinearExpression()
-		ubDiff.AddTerm(deviceUsageUB, -1)
-		ubDiff.AddVar(globalUsageUB)
-		solver.AddConstraintExpr(ubDiff, mip.GreaterThanOrEqual, 0)
+		deviceUB := mip.NewLinearExpression()
+		deviceUB.AddVar(deviceUsageUB)
+		globalUB := mip.NewLinearExpression()
+		globalUB.AddVar(globalUsageUB)
+		solver.AddConstraint(deviceUB, mip.LessThanOrEqual, globalUB)
 	}
 
 	// Define weights / importance for the objective function
@@ -164,12 +163,12 @@ func RoutingCtrlLinkSelection(timeLimit time.Duration) {
 	// Solve the problem with a time limit
 	// Note that the solver will return the best solution found within the time
 	// limit, with or without optimality guarantees.
-	isOptimal, err := solver.Solve(timeLimit)
+	result, err := solver.Solve(timeLimit)
 	if err != nil {
 		log.Fatalf("Solver error: %v", err)
 	}
 
-	if isOptimal {
+	if result.IsOptimal() {
 		fmt.Println("The objective is guaranteed to be optimal.")
 	} else {
 		fmt.Println("Suboptimal feasible solution found within time limit.")
@@ -185,11 +184,11 @@ func RoutingCtrlLinkSelection(timeLimit time.Duration) {
 		"Gap (%)",
 	})
 
-	gapPercentage := solver.Gap() * 100
+	gapPercentage := result.Gap * 100
 	summary.AppendRow(table.Row{
-		fmt.Sprintf("%.2f", solver.ObjectiveValue()),
+		fmt.Sprintf("%.2f", result.ObjectiveValue),
 		fmt.Sprintf("%.2f", globalUsageUB.Value()),
-		fmt.Sprintf("%.2f", solver.BestBound()),
+		fmt.Sprintf("%.2f", result.BestBound),
 		fmt.Sprintf("%.2f%%", gapPercentage),
 	})
 	summary.Render()
@@ -40,12 +40,12 @@ func KnapsackProblem() {
 	}
 	solver.SetObjective(obj, mip.Maximize)
 
-	foundOptimal, err := solver.Solve(-1) // no time limit
+	result, err := solver.Solve(-1) // no time limit
 	if err != nil {
 		log.Fatalf("Error solving the problem: %v", err)
 	}
 
-	if !foundOptimal {
+	if !result.IsOptimal() {
 		fmt.Println("Solver finished within the time limit without finding the optimal solution.")
 	} else {
 		fmt.Println("Optimal solution found")
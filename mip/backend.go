@@ -0,0 +1,151 @@
+package mip
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Backend is a solver engine that a Model is materialised into when it is solved.
+// The OR-Tools bridge is the default implementation, other engines, remote solvers or mocks
+// can be plugged in with NewSolverWithBackend.
+//
+// Variables and constraints are identified by the order in which they were added to the Backend, starting at 0.
+// A Backend is loaded incrementally: between two calls to Solve, only the newly added variables and constraints
+// and the modified variables and constraint bounds are passed to it, and the objective coefficients are set again.
+// The variables and constraints of the Backend are those of the Model, in the same order, interleaved with the
+// variables and rows reformulating what the Backend does not support natively, e.g. indicator constraints.
+type Backend interface {
+	// AddVariable adds a variable with the given bounds. The name may be empty and may contain any UTF-8 text.
+	AddVariable(name string, lb, ub float64, integer bool)
+	// AddConstraint adds the row lb <= sum(coeffs[i] * variable vars[i]) <= ub. The name may be empty.
+	AddConstraint(name string, lb, ub float64, vars []int, coeffs []float64)
+	// SetConstraintBounds changes the bounds of a constraint.
+	SetConstraintBounds(constraint int, lb, ub float64)
+	// SetVariableBounds changes the bounds of a variable.
+	SetVariableBounds(variable int, lb, ub float64)
+	// SetVariableInteger changes whether a variable is integer.
+	SetVariableInteger(variable int, integer bool)
+	// SetObjectiveCoefficient sets the coefficient of a variable in the objective.
+	SetObjectiveCoefficient(variable int, coeff float64)
+	// SetObjectiveOffset sets the constant term of the objective.
+	SetObjectiveOffset(offset float64)
+	// SetOptimizationType sets whether the objective is maximized or minimized.
+	SetOptimizationType(tp OptimizationType)
+	// SetTimeLimit limits the duration of the next solves. A non-positive duration means no limit.
+	SetTimeLimit(timeLimit time.Duration)
+	// Solve solves the problem loaded so far.
+	Solve() ResultStatus
+	// ObjectiveValue returns the objective value of the best solution found by the last Solve.
+	ObjectiveValue() float64
+	// BestBound returns the best bound on the optimal objective value proven by the last Solve.
+	BestBound() float64
+	// Iterations returns the number of simplex iterations performed by the last Solve.
+	Iterations() int64
+	// Nodes returns the number of branch-and-bound nodes explored by the last Solve, or -1 if unknown.
+	Nodes() int64
+	// Value returns the value of a variable in the best solution found by the last Solve.
+	Value(variable int) float64
+	// Release frees up the resources held by the Backend. The Backend is not used afterward.
+	Release()
+}
+
+// Interrupter is implemented by Backends whose Solve can be stopped from another goroutine.
+type Interrupter interface {
+	// Interrupt asks the running Solve to stop as soon as possible, keeping the best solution found so far.
+	// It reports whether the underlying solver supports interruption.
+	Interrupt() bool
+}
+
+// ParamSetter is implemented by Backends accepting SolverParams.
+type ParamSetter interface {
+	// SetParams sets the parameters used by the next solves.
+	// It returns an error if a parameter is not supported by the underlying solver.
+	SetParams(p SolverParams) error
+}
+
+// Hinter is implemented by Backends accepting solution hints (warm starts).
+type Hinter interface {
+	// SetHint sets the hint used by the next solves, replacing any previous one, an empty hint clears it.
+	// It reports whether the underlying solver makes use of hints.
+	SetHint(vars []int, values []float64) bool
+}
+
+// DualReader is implemented by Backends exposing the dual information of linear programs.
+type DualReader interface {
+	// HasDuals reports whether the last Solve produced dual information, i.e. it solved a linear program.
+	HasDuals() bool
+	// DualValue returns the dual value of a constraint in the last solution.
+	DualValue(constraint int) float64
+	// ConstraintBasisStatus returns the basis status of a constraint in the last solution.
+	ConstraintBasisStatus(constraint int) BasisStatus
+	// ReducedCost returns the reduced cost of a variable in the last solution.
+	ReducedCost(variable int) float64
+	// VariableBasisStatus returns the basis status of a variable in the last solution.
+	VariableBasisStatus(variable int) BasisStatus
+}
+
+// IndicatorAdder is implemented by Backends that may support indicator constraints natively.
+// Indicator constraints are not counted among the constraints identified by their order, nor are special ordered sets.
+type IndicatorAdder interface {
+	// AddIndicatorConstraint adds the row lb <= sum(coeffs[i] * variable vars[i]) <= ub, enforced only when the binary
+	// variable indicator equals 1 if active is true, 0 otherwise. It reports whether the underlying solver supports
+	// indicator constraints, nothing is added if it does not.
+	AddIndicatorConstraint(indicator int, active bool, lb, ub float64, vars []int, coeffs []float64) bool
+}
+
+// SOSAdder is implemented by Backends that may support special ordered sets natively.
+type SOSAdder interface {
+	// AddSOS adds a special ordered set of the given type over the variables, sorted by increasing weights.
+	// It reports whether the underlying solver supports special ordered sets, nothing is added if it does not.
+	AddSOS(sosType SOSType, vars []int, weights []float64) bool
+}
+
+var (
+	leakReporterMu sync.Mutex
+	leakReporter   func(leak string)
+)
+
+// SetLeakReporter sets a function called with a description of each solver whose resources were garbage collected
+// without ReleaseResources being called, e.g. to log or fail tests on leaks. The resources are freed anyway.
+// The function is called from a finalizer and must not block. A nil function disables the reports.
+func SetLeakReporter(report func(leak string)) {
+	leakReporterMu.Lock()
+	defer leakReporterMu.Unlock()
+	leakReporter = report
+}
+
+func reportLeak(leak string) {
+	leakReporterMu.Lock()
+	report := leakReporter
+	leakReporterMu.Unlock()
+	if report != nil {
+		report(leak)
+	}
+}
+
+// setLeakFinalizer makes the garbage collection of obj report the leak and call free, until the finalizer
+// is cleared with runtime.SetFinalizer(obj, nil) once obj is released.
+func setLeakFinalizer[T any](obj *T, leak string, free func(*T)) {
+	runtime.SetFinalizer(obj, func(obj *T) {
+		reportLeak(leak)
+		free(obj)
+	})
+}
+
+// ErrorReporter is implemented by Backends whose operations can fail, e.g. on invalid input or internal errors
+// of the underlying solver. The Solver checks it after loading the model and after each Solve.
+type ErrorReporter interface {
+	// Err returns the first error encountered by the Backend, nil if there is none.
+	Err() error
+}
+
+// isSolverTypeAvailable reports whether the default Backend can create a solver of the given type.
+// It is set by the OR-Tools bridge when it is compiled in.
+var isSolverTypeAvailable = func(solverType string) bool { return false }
+
+// newDefaultBackend creates the Backend used by NewSolver. It is set by the OR-Tools bridge when it is compiled in.
+var newDefaultBackend = func(solverType string) (Backend, error) {
+	return nil, fmt.Errorf("the OR-Tools bridge is not available, this package was built without cgo")
+}
@@ -0,0 +1,80 @@
+package mip
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewSolverUsesDefaultBackend(t *testing.T) {
+	defer func(available func(string) bool, newBackend func(string) (Backend, error)) {
+		isSolverTypeAvailable, newDefaultBackend = available, newBackend
+	}(isSolverTypeAvailable, newDefaultBackend)
+	isSolverTypeAvailable = func(solverType string) bool { return solverType == CBC || solverType == HIGHS }
+	var created []string
+	b := &testBackend{}
+	newDefaultBackend = func(solverType string) (Backend, error) {
+		created = append(created, solverType)
+		return b, nil
+	}
+
+	s, err := NewSolver(CBC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.VarBool("x")
+	if _, err := s.Solve(0); err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 1 || created[0] != CBC || len(b.columns) != 1 {
+		t.Errorf("got the backends %v with %d columns, want one CBC backend with 1 column", created, len(b.columns))
+	}
+	if _, err := NewSolver("MOSEK"); err == nil || !strings.Contains(err.Error(), "unsupported solver type") {
+		t.Errorf("got %v, want an error for an unknown solver type", err)
+	}
+
+	newDefaultBackend = func(string) (Backend, error) { return nil, errors.New("HiGHS failed to start") }
+	if _, err := NewSolver(HIGHS); err == nil || err.Error() != "HiGHS failed to start" {
+		t.Errorf("got %v, want the error creating the backend", err)
+	}
+}
+
+func TestAvailableSolverTypes(t *testing.T) {
+	defer func(available func(string) bool) { isSolverTypeAvailable = available }(isSolverTypeAvailable)
+	isSolverTypeAvailable = func(solverType string) bool { return solverType == CBC || solverType == HIGHS }
+
+	if got := strings.Join(AvailableSolverTypes(), ","); got != "CBC,HIGHS" {
+		t.Errorf("got %s, want CBC,HIGHS", got)
+	}
+	if _, err := NewSolver(SCIP); err == nil || !strings.Contains(err.Error(), "SCIP is not available") ||
+		!strings.HasSuffix(err.Error(), "available types are: CBC, HIGHS") {
+		t.Errorf("got %v, want an error listing CBC and HIGHS", err)
+	}
+
+	isSolverTypeAvailable = func(string) bool { return false }
+	if _, err := NewSolver(SCIP); err == nil || !strings.HasSuffix(err.Error(), "available types are: none") {
+		t.Errorf("got %v, want an error as no solver is available", err)
+	}
+}
+
+func TestSolveReportsBackendErrors(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 3)
+	m.AddConstraintExpr(sumOf(x), LessThanOrEqual, 2)
+
+	s, b := newTestSolver(m)
+	b.err = errors.New("invalid row")
+	if _, err := s.Solve(0); err == nil || !strings.Contains(err.Error(), "loading the model into the solver: invalid row") || b.solves != 0 {
+		t.Errorf("got %v after %d solves, want the load error without solving", err, b.solves)
+	}
+
+	s, b = newTestSolver(m)
+	b.onSolve = func(status ResultStatus) ResultStatus {
+		b.err = errors.New("solver crashed")
+		return status
+	}
+	result, err := s.Solve(0)
+	if !errors.Is(err, ErrAbnormal) || !strings.Contains(err.Error(), "solver crashed") || result.Status != Abnormal || result.HasSolution() {
+		t.Errorf("got %+v, %v, want an Abnormal result without solution", result, err)
+	}
+}
@@ -0,0 +1,231 @@
+//go:build cgo
+
+package mip
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// bridgeBackend is the Backend backed by an OR-Tools MPSolver through the C bridge.
+// Empty variable and constraint names are replaced by generated names and every name is made unique among
+// the variables or the constraints, so solver logs and models exported by OR-Tools can always refer to them.
+// It does not implement SOSAdder: MPSolver only accepts special ordered sets in direct proto solves, which would
+// bypass the incremental model, so they are reformulated with binary variables.
+type bridgeBackend struct {
+	solverType string
+	solver     *solver
+	vars       []*variable
+	cons       []*constraint
+	varNames   map[string]bool
+	consNames  map[string]bool
+
+	pendingGeneral bool // general constraints were added since the last Solve
+}
+
+func init() {
+	newDefaultBackend = NewBridgeBackend
+	isSolverTypeAvailable = solverTypeAvailable
+}
+
+// NewBridgeBackend creates a Backend running the given OR-Tools solver (e.g. CBC or SCIP) through the C bridge.
+func NewBridgeBackend(solverType string) (Backend, error) {
+	s, err := createSolver(solverType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Solver: %w", err)
+	}
+	return &bridgeBackend{solverType: solverType, solver: s, varNames: map[string]bool{}, consNames: map[string]bool{}}, nil
+}
+
+// uniqueName records a name given to the solver, generating one from the prefix and the index if it is empty.
+// A name already given, by the user or generated, is suffixed with underscores until it is unique.
+func uniqueName(names map[string]bool, name, prefix string, index int) string {
+	if name == "" {
+		name = fmt.Sprintf("%s%d", prefix, index)
+	}
+	for names[name] {
+		name += "_"
+	}
+	names[name] = true
+	return name
+}
+
+func (b *bridgeBackend) AddVariable(name string, lb, ub float64, integer bool) {
+	varType := 0
+	if integer {
+		varType = 1
+	}
+	name = uniqueName(b.varNames, name, "_v", len(b.vars))
+	b.vars = append(b.vars, b.solver.newVariable(name, lb, ub, varType))
+}
+
+// Err returns the first error reported by the bridge. Once an operation failed, later changes to the model
+// are ignored, as the handles of the variables or constraints that failed to be created are missing.
+func (b *bridgeBackend) Err() error { return b.solver.err() }
+
+func (b *bridgeBackend) AddConstraint(name string, lb, ub float64, vars []int, coeffs []float64) {
+	if b.Err() != nil {
+		return
+	}
+	name = uniqueName(b.consNames, name, "_c", len(b.cons))
+	row := b.solver.newConstraint(name, lb, ub)
+	for i, v := range vars {
+		row.setCoefficient(b.vars[v], coeffs[i])
+	}
+	b.cons = append(b.cons, row)
+}
+
+func (b *bridgeBackend) SetConstraintBounds(constraint int, lb, ub float64) {
+	if b.Err() != nil {
+		return
+	}
+	b.cons[constraint].setBounds(lb, ub)
+}
+
+func (b *bridgeBackend) SetVariableBounds(variable int, lb, ub float64) {
+	if b.Err() != nil {
+		return
+	}
+	b.vars[variable].setBounds(lb, ub)
+}
+
+func (b *bridgeBackend) SetVariableInteger(variable int, integer bool) {
+	if b.Err() != nil {
+		return
+	}
+	b.vars[variable].setInteger(integer)
+}
+
+func (b *bridgeBackend) SetObjectiveCoefficient(variable int, coeff float64) {
+	if b.Err() != nil {
+		return
+	}
+	b.solver.setObjectiveCoefficient(b.vars[variable], coeff)
+}
+
+func (b *bridgeBackend) SetObjectiveOffset(offset float64) {
+	b.solver.setObjectiveOffset(offset)
+}
+
+func (b *bridgeBackend) SetOptimizationType(tp OptimizationType) {
+	switch tp {
+	case Maximize:
+		b.solver.setMaximization()
+	case Minimize:
+		b.solver.setMinimization()
+	}
+}
+
+func (b *bridgeBackend) SetTimeLimit(timeLimit time.Duration) {
+	b.solver.setTimeLimit(timeLimit.Milliseconds()) // the bridge removes the limit if it is not positive
+}
+
+func (b *bridgeBackend) SetParams(p SolverParams) error {
+	b.solver.setRelativeMipGap(p.RelativeGap) // reset to default if not positive
+
+	switch p.Presolve {
+	case PresolveOn:
+		b.solver.setPresolve(1)
+	case PresolveOff:
+		b.solver.setPresolve(0)
+	default:
+		b.solver.setPresolve(-1)
+	}
+
+	// values of MPSolverParameters::LpAlgorithmValues
+	switch p.LPAlgorithm {
+	case DualSimplex:
+		b.solver.setLpAlgorithm(10)
+	case PrimalSimplex:
+		b.solver.setLpAlgorithm(11)
+	case Barrier:
+		b.solver.setLpAlgorithm(12)
+	default:
+		b.solver.setLpAlgorithm(0)
+	}
+
+	if p.NumThreads > 0 {
+		if err := b.solver.setNumThreads(p.NumThreads); err != nil {
+			return fmt.Errorf("%s does not support setting the number of threads: %w", b.solverType, err)
+		}
+	}
+
+	b.solver.enableOutput(p.Verbose)
+
+	// the absolute gap and the seed have no generic OR-Tools parameter
+	specific, err := p.specificParams(b.solverType)
+	if err != nil {
+		return err
+	}
+
+	// always set, so that parameters of a previous call are cleared
+	if err := b.solver.setSolverSpecificParameters(strings.Join(specific, "\n")); err != nil {
+		return fmt.Errorf("%s rejected the solver specific parameters: %w", b.solverType, err)
+	}
+	return nil
+}
+
+// solversUsingHints are the solvers for which OR-Tools passes the hint on.
+var solversUsingHints = map[string]bool{SCIP: true, CPSAT: true, GUROBI: true, XPRESS: true}
+
+func (b *bridgeBackend) SetHint(vars []int, values []float64) bool {
+	if b.Err() != nil {
+		return false
+	}
+	hintVars := make([]*variable, len(vars))
+	for i, v := range vars {
+		hintVars[i] = b.vars[v]
+	}
+	b.solver.setHint(hintVars, values)
+	return solversUsingHints[b.solverType]
+}
+
+// solversWithIndicators are the solvers to which OR-Tools passes indicator constraints.
+var solversWithIndicators = map[string]bool{SCIP: true, CPSAT: true, GUROBI: true}
+
+func (b *bridgeBackend) AddIndicatorConstraint(indicator int, active bool, lb, ub float64, vars []int, coeffs []float64) bool {
+	if !solversWithIndicators[b.solverType] {
+		return false
+	}
+	if b.Err() != nil {
+		return true
+	}
+	rowVars := make([]*variable, len(vars))
+	for i, v := range vars {
+		rowVars[i] = b.vars[v]
+	}
+	b.solver.addIndicatorConstraint(b.vars[indicator], active, lb, ub, rowVars, coeffs)
+	b.pendingGeneral = true
+	return true
+}
+
+func (b *bridgeBackend) Solve() ResultStatus {
+	if b.Err() != nil {
+		return Abnormal
+	}
+	if b.pendingGeneral {
+		b.solver.loadGeneralConstraints(b.vars, b.cons)
+		b.pendingGeneral = false
+		if b.Err() != nil {
+			return Abnormal
+		}
+	}
+	return ResultStatus(b.solver.solve())
+}
+func (b *bridgeBackend) ObjectiveValue() float64          { return b.solver.objectiveValue() }
+func (b *bridgeBackend) BestBound() float64               { return b.solver.getBestBound() }
+func (b *bridgeBackend) Interrupt() bool                  { return b.solver.interruptSolve() }
+func (b *bridgeBackend) HasDuals() bool                   { return !b.solver.isMip() }
+func (b *bridgeBackend) DualValue(constraint int) float64 { return b.cons[constraint].dualValue() }
+func (b *bridgeBackend) ConstraintBasisStatus(constraint int) BasisStatus {
+	return BasisStatus(b.cons[constraint].basisStatus())
+}
+func (b *bridgeBackend) ReducedCost(variable int) float64 { return b.vars[variable].reducedCost() }
+func (b *bridgeBackend) VariableBasisStatus(variable int) BasisStatus {
+	return BasisStatus(b.vars[variable].basisStatus())
+}
+func (b *bridgeBackend) Iterations() int64          { return b.solver.iterations() }
+func (b *bridgeBackend) Nodes() int64               { return b.solver.nodes() }
+func (b *bridgeBackend) Value(variable int) float64 { return b.vars[variable].solutionValue() }
+func (b *bridgeBackend) Release()                   { b.solver.delete() }
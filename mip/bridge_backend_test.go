@@ -0,0 +1,19 @@
+//go:build cgo
+
+package mip
+
+import "testing"
+
+func TestUniqueName(t *testing.T) {
+	names := map[string]bool{}
+	var got []string
+	for i, name := range []string{"", "_v0", "x", "x", ""} {
+		got = append(got, uniqueName(names, name, "_v", i))
+	}
+	want := []string{"_v0", "_v0_", "x", "x_", "_v4"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got names %v, want %v", got, want)
+		}
+	}
+}
@@ -8,6 +8,10 @@ package mip
 */
 import "C"
 import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
 	"unsafe"
 )
 
@@ -16,43 +20,330 @@ import (
 // Nothing from this file is exported outside of this package in order
 // to separate the translation code from the actual exported mip API.
 
-type solver struct{ csolver *C.CSolver }
+// call runs a bridge function and turns a BRIDGE_ERROR status into an error with the message of LastError.
+// The goroutine is locked to its thread, as the last error is kept per thread.
+func call(f func() C.int) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
 
-func createSolver(solverType string) *solver {
+	if f() == C.BRIDGE_OK {
+		return nil
+	}
+	msg := goString(func(buf *C.char, bufLen C.int, n *C.int) C.int { return C.LastError(buf, bufLen, n) })
+	return errors.New("OR-Tools: " + msg)
+}
+
+// solver owns the C++ MPSolver, the variables and constraints handles are only valid until it is deleted.
+// A solver that is garbage collected without being deleted is reported as a leak and deleted by its finalizer.
+//
+// The first error of a call that has no error result is kept, see err.
+type solver struct {
+	csolver    *C.CSolver // nil once deleted
+	solverType string
+
+	mu       sync.Mutex // guards firstErr, which is also set by interruptSolve during a solve
+	firstErr error
+}
+
+func solverTypeAvailable(solverType string) bool {
+	cName := C.CString(solverType)
+	defer C.free(unsafe.Pointer(cName))
+	var available C.int
+	if err := call(func() C.int { return C.SolverTypeAvailable(cName, &available) }); err != nil {
+		return false
+	}
+	return available != 0
+}
+
+func createSolver(solverType string) (*solver, error) {
 	cName := C.CString(solverType)
 	defer C.free(unsafe.Pointer(cName))
-	return &solver{C.CreateSolver(cName)}
+	var csolver *C.CSolver
+	if err := call(func() C.int { return C.CreateSolver(cName, &csolver) }); err != nil {
+		return nil, err
+	}
+	s := &solver{csolver: csolver, solverType: solverType}
+	setLeakFinalizer(s, fmt.Sprintf("OR-Tools %s solver garbage collected without ReleaseResources", solverType), (*solver).delete)
+	return s, nil
+}
+
+// ptr returns the C solver, it panics if the solver was deleted rather than passing a dangling pointer to C.
+func (s *solver) ptr() *C.CSolver {
+	if s.csolver == nil {
+		panic("mip: OR-Tools solver used after ReleaseResources")
+	}
+	return s.csolver
+}
+
+// check records err if it is the first error of the solver.
+func (s *solver) check(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil && s.firstErr == nil {
+		s.firstErr = err
+	}
+}
+
+// err returns the first error of the solver, nil if all calls succeeded.
+func (s *solver) err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.firstErr
 }
 
-func (s *solver) delete()                     { C.DeleteSolver(s.csolver) }
-func (s *solver) setMaximization()            { C.SetMaximization(s.csolver) }
-func (s *solver) setMinimization()            { C.SetMinimization(s.csolver) }
-func (s *solver) setTimeLimit(duration int64) { C.SetTimeLimit(s.csolver, C.int(duration)) }
-func (s *solver) solve() int                  { return int(C.Solve(s.csolver)) }
-func (s *solver) objectiveValue() float64     { return float64(C.ObjectiveValue(s.csolver)) }
-func (s *solver) getBestBound() float64       { return float64(C.GetBestBound(s.csolver)) }
+// delete frees the C solver, it can be called several times.
+func (s *solver) delete() {
+	if s.csolver == nil {
+		return
+	}
+	csolver := s.csolver
+	s.check(call(func() C.int { return C.DeleteSolver(csolver) }))
+	s.csolver = nil
+	runtime.SetFinalizer(s, nil)
+}
+func (s *solver) setMaximization() {
+	s.check(call(func() C.int { return C.SetMaximization(s.ptr()) }))
+}
+func (s *solver) setMinimization() {
+	s.check(call(func() C.int { return C.SetMinimization(s.ptr()) }))
+}
+func (s *solver) setTimeLimit(duration int64) {
+	s.check(call(func() C.int { return C.SetTimeLimit(s.ptr(), C.int(duration)) }))
+}
+func (s *solver) interruptSolve() bool {
+	var supported C.int
+	s.check(call(func() C.int { return C.InterruptSolve(s.ptr(), &supported) }))
+	return supported != 0
+}
+func (s *solver) objectiveValue() float64 {
+	var value C.double
+	s.check(call(func() C.int { return C.ObjectiveValue(s.ptr(), &value) }))
+	return float64(value)
+}
+func (s *solver) getBestBound() float64 {
+	var value C.double
+	s.check(call(func() C.int { return C.GetBestBound(s.ptr(), &value) }))
+	return float64(value)
+}
+func (s *solver) isMip() bool {
+	var isMip C.int
+	s.check(call(func() C.int { return C.IsMip(s.ptr(), &isMip) }))
+	return isMip != 0
+}
+func (s *solver) iterations() int64 {
+	var iterations C.longlong
+	s.check(call(func() C.int { return C.Iterations(s.ptr(), &iterations) }))
+	return int64(iterations)
+}
+func (s *solver) nodes() int64 {
+	var nodes C.longlong
+	s.check(call(func() C.int { return C.Nodes(s.ptr(), &nodes) }))
+	return int64(nodes)
+}
+func (s *solver) setRelativeMipGap(gap float64) {
+	s.check(call(func() C.int { return C.SetRelativeMipGap(s.ptr(), C.double(gap)) }))
+}
+func (s *solver) setPresolve(presolve int) {
+	s.check(call(func() C.int { return C.SetPresolve(s.ptr(), C.int(presolve)) }))
+}
+func (s *solver) setLpAlgorithm(algorithm int) {
+	s.check(call(func() C.int { return C.SetLpAlgorithm(s.ptr(), C.int(algorithm)) }))
+}
+
+// setNumThreads returns an error if the solver does not support the number of threads, it is not recorded.
+func (s *solver) setNumThreads(n int) error {
+	return call(func() C.int { return C.SetNumThreads(s.ptr(), C.int(n)) })
+}
+func (s *solver) solve() int {
+	var status C.int
+	if err := call(func() C.int { return C.Solve(s.ptr(), &status) }); err != nil {
+		s.check(err)
+		status = C.int(Abnormal)
+	}
+	runtime.KeepAlive(s) // the finalizer must not delete the solver during a long solve
+	return int(status)
+}
+func (s *solver) enableOutput(enable bool) {
+	cEnable := 0
+	if enable {
+		cEnable = 1
+	}
+	s.check(call(func() C.int { return C.EnableOutput(s.ptr(), C.int(cEnable)) }))
+}
+
+// setSolverSpecificParameters returns an error if the solver rejects the parameters, it is not recorded.
+func (s *solver) setSolverSpecificParameters(parameters string) error {
+	cParameters := C.CString(parameters)
+	defer C.free(unsafe.Pointer(cParameters))
+	return call(func() C.int { return C.SetSolverSpecificParameters(s.ptr(), cParameters) })
+}
+func (s *solver) setHint(vars []*variable, values []float64) {
+	cVars := make([]*C.CVariable, len(vars))
+	for i, v := range vars {
+		cVars[i] = v.ptr()
+	}
+	var cVarsPtr **C.CVariable
+	var cValuesPtr *C.double
+	if len(vars) > 0 {
+		cVarsPtr = &cVars[0]
+		cValuesPtr = (*C.double)(unsafe.Pointer(&values[0]))
+	}
+	s.check(call(func() C.int { return C.SetHint(s.ptr(), cVarsPtr, cValuesPtr, C.int(len(vars))) }))
+}
+func (s *solver) addIndicatorConstraint(indicator *variable, active bool, lb, ub float64, vars []*variable, coeffs []float64) {
+	cActive := 0
+	if active {
+		cActive = 1
+	}
+	cVars := make([]*C.CVariable, len(vars))
+	for i, v := range vars {
+		cVars[i] = v.ptr()
+	}
+	var cVarsPtr **C.CVariable
+	var cCoeffsPtr *C.double
+	if len(vars) > 0 {
+		cVarsPtr = &cVars[0]
+		cCoeffsPtr = (*C.double)(unsafe.Pointer(&coeffs[0]))
+	}
+	s.check(call(func() C.int {
+		return C.AddIndicatorConstraint(s.ptr(), indicator.ptr(), C.int(cActive), C.double(lb), C.double(ub), cVarsPtr, cCoeffsPtr, C.int(len(vars)))
+	}))
+}
+
+// loadGeneralConstraints passes the pending general constraints to the solver, which reloads its model,
+// and points the variables and constraints, the linear constraints of the solver in order, to their new handles.
+func (s *solver) loadGeneralConstraints(vars []*variable, cons []*constraint) {
+	if err := call(func() C.int { return C.LoadGeneralConstraints(s.ptr()) }); err != nil {
+		s.check(err)
+		return
+	}
+	for i, v := range vars {
+		s.check(call(func() C.int { return C.SolverVariable(s.ptr(), C.int(i), &v.cvariable) }))
+	}
+	for i, c := range cons {
+		s.check(call(func() C.int { return C.SolverConstraint(s.ptr(), C.int(i), &c.cconstraint) }))
+	}
+}
+func (s *solver) setObjectiveOffset(offset float64) {
+	s.check(call(func() C.int { return C.SetObjectiveOffset(s.ptr(), C.double(offset)) }))
+}
 func (s *solver) setObjectiveCoefficient(variable *variable, coeff float64) {
-	C.SetObjectiveCoefficient(s.csolver, variable.cvariable, C.double(coeff))
+	s.check(call(func() C.int { return C.SetObjectiveCoefficient(s.ptr(), variable.ptr(), C.double(coeff)) }))
 }
 
-type variable struct{ cvariable *C.CVariable }
+type variable struct {
+	cvariable *C.CVariable
+	solver    *solver
+}
+
+func (v *variable) ptr() *C.CVariable {
+	v.solver.ptr()
+	return v.cvariable
+}
 
+// newVariable returns nil if the variable could not be created, the error is recorded by the solver.
 func (s *solver) newVariable(name string, lb, ub float64, varType int) *variable {
 	// varType: 0 - continuous, 1 - integer
-	cName := C.CString(name)
-	defer C.free(unsafe.Pointer(cName))
-	return &variable{C.AddVar(s.csolver, cName, C.double(lb), C.double(ub), C.int(varType))}
+	cName, cNameLen := cString(name)
+	var cvariable *C.CVariable
+	err := call(func() C.int {
+		return C.AddVar(s.ptr(), cName, cNameLen, C.double(lb), C.double(ub), C.int(varType), &cvariable)
+	})
+	if err != nil {
+		s.check(fmt.Errorf("variable %s: %w", name, err))
+		return nil
+	}
+	return &variable{cvariable, s}
+}
+
+func (v *variable) solutionValue() float64 {
+	var value C.double
+	v.solver.check(call(func() C.int { return C.SolutionValue(v.ptr(), &value) }))
+	return float64(value)
+}
+func (v *variable) reducedCost() float64 {
+	var value C.double
+	v.solver.check(call(func() C.int { return C.ReducedCost(v.ptr(), &value) }))
+	return float64(value)
+}
+func (v *variable) basisStatus() int {
+	var status C.int
+	v.solver.check(call(func() C.int { return C.VariableBasisStatus(v.ptr(), &status) }))
+	return int(status)
+}
+func (v *variable) setBounds(lb, ub float64) {
+	v.solver.check(call(func() C.int { return C.SetVariableBounds(v.ptr(), C.double(lb), C.double(ub)) }))
+}
+func (v *variable) setInteger(isInteger bool) {
+	cIsInteger := 0
+	if isInteger {
+		cIsInteger = 1
+	}
+	v.solver.check(call(func() C.int { return C.SetVariableInteger(v.ptr(), C.int(cIsInteger)) }))
+}
+func (v *variable) name() string {
+	return goString(func(buf *C.char, bufLen C.int, n *C.int) C.int { return C.VariableName(v.ptr(), buf, bufLen, n) })
 }
 
-func (v *variable) solutionValue() float64 { return float64(C.SolutionValue(v.cvariable)) }
-func (v *variable) name() string           { return C.GoString(C.VariableName(unsafe.Pointer(v.cvariable))) }
+type constraint struct {
+	cconstraint *C.CConstraint
+	solver      *solver
+}
+
+func (c *constraint) ptr() *C.CConstraint {
+	c.solver.ptr()
+	return c.cconstraint
+}
 
-type constraint struct{ cconstraint *C.CConstraint }
+// newConstraint returns nil if the constraint could not be created, the error is recorded by the solver.
+func (s *solver) newConstraint(name string, lb, ub float64) *constraint {
+	cName, cNameLen := cString(name)
+	var cconstraint *C.CConstraint
+	err := call(func() C.int {
+		return C.AddConstraint(s.ptr(), cName, cNameLen, C.double(lb), C.double(ub), &cconstraint)
+	})
+	if err != nil {
+		s.check(fmt.Errorf("constraint %s: %w", name, err))
+		return nil
+	}
+	return &constraint{cconstraint, s}
+}
 
-func (s *solver) newConstraint(lb, ub float64) *constraint {
-	return &constraint{cconstraint: C.AddConstraint(s.csolver, C.double(lb), C.double(ub))}
+func (c *constraint) name() string {
+	return goString(func(buf *C.char, bufLen C.int, n *C.int) C.int { return C.ConstraintName(c.ptr(), buf, bufLen, n) })
 }
 
+func (c *constraint) dualValue() float64 {
+	var value C.double
+	c.solver.check(call(func() C.int { return C.DualValue(c.ptr(), &value) }))
+	return float64(value)
+}
+func (c *constraint) basisStatus() int {
+	var status C.int
+	c.solver.check(call(func() C.int { return C.ConstraintBasisStatus(c.ptr(), &status) }))
+	return int(status)
+}
+func (c *constraint) setBounds(lb, ub float64) {
+	c.solver.check(call(func() C.int { return C.SetConstraintBounds(c.ptr(), C.double(lb), C.double(ub)) }))
+}
 func (c *constraint) setCoefficient(v *variable, coeff float64) {
-	C.SetCoefficient(c.cconstraint, v.cvariable, C.double(coeff))
+	c.solver.check(call(func() C.int { return C.SetCoefficient(c.ptr(), v.ptr(), C.double(coeff)) }))
+}
+
+// cString returns the bytes of a Go string and its length for the bridge functions taking names,
+// which copy them: no C allocation is needed and names may contain any byte.
+func cString(s string) (*C.char, C.int) {
+	return (*C.char)(unsafe.Pointer(unsafe.StringData(s))), C.int(len(s))
+}
+
+// goString copies a string out of the bridge into Go memory, with a bridge function writing
+// at most bufLen bytes into buf and the length of the string to n. Errors read an empty string.
+func goString(read func(buf *C.char, bufLen C.int, n *C.int) C.int) string {
+	var n C.int
+	if read(nil, 0, &n) != C.BRIDGE_OK || n == 0 {
+		return ""
+	}
+	buf := make([]byte, n)
+	read((*C.char)(unsafe.Pointer(&buf[0])), n, &n)
+	return string(buf[:min(int(n), len(buf))])
 }
@@ -7,7 +7,19 @@ import (
 
 // Constraint represents a linear constraint in the form of:
 // a1*x1 + a2*x2 + ... + a_n*x_n {<=, >=, ==} b
-type Constraint = constraint // export alias
+// internally stored as lb <= a1*x1 + a2*x2 + ... + a_n*x_n <= ub
+type Constraint struct {
+	index      int
+	name       string
+	lowerBound float64
+	upperBound float64
+	expr       *LinearExpression
+
+	// information from the most recent solution
+	activity    float64
+	dualValue   float64
+	basisStatus BasisStatus
+}
 
 // ConstraintType represents the sign between the linear expression and the right-hand side constant in a Constraint.
 // Recall that a linear Constraint is in the form of a1*x1 + a2*x2 + ... + an*xn {<=, >=, ==} b
@@ -19,29 +31,147 @@ const (
 	GreaterThanOrEqual = ">="
 )
 
-// AddConstraintExpr adds a new Constraint to the Solver based on the given linear expression and Constraint type.
+// AddConstraintExpr adds a new Constraint to the Model based on the given linear expression and Constraint type.
 // For example if we want expression <= 5, we would call AddConstraintExpr(expr, LessThanOrEqual, 5.0)
-func (s *Solver) AddConstraintExpr(e *LinearExpression, t ConstraintType, rhs float64) *Constraint {
-	var c *Constraint
+// The expression is copied, later changes to it do not affect the Constraint.
+// The constant of the expression is moved to the right-hand side: x + 2 <= 5 is stored as x <= 3.
+func (m *Model) AddConstraintExpr(e *LinearExpression, t ConstraintType, rhs float64) *Constraint {
+	lb, ub := constraintBounds(t, rhs)
+	return m.addConstraint("", lb, ub, e)
+}
+
+// AddConstraint adds a new Constraint comparing two linear expressions, i.e. lhs <= rhs for LessThanOrEqual.
+// It is stored as lhs - rhs t 0, with the constants of both sides moved to the right-hand side.
+func (m *Model) AddConstraint(lhs *LinearExpression, t ConstraintType, rhs *LinearExpression) *Constraint {
+	e := lhs.Clone()
+	e.Sub(rhs)
+	lb, ub := constraintBounds(t, 0)
+	return m.addConstraint("", lb, ub, e)
+}
+
+// AddRangeConstraint adds the two-sided Constraint lb <= e <= ub to the Model.
+// Either bound may be infinite, it panics if lb > ub.
+func (m *Model) AddRangeConstraint(lb float64, e *LinearExpression, ub float64) *Constraint {
+	if lb > ub {
+		panic(fmt.Sprintf("Invalid range constraint: lower bound %v is greater than upper bound %v", lb, ub))
+	}
+	return m.addConstraint("", lb, ub, e)
+}
+
+// AddNamedConstraint is like AddConstraintExpr, for a named Constraint that can be retrieved with ConstraintByName.
+// The name appears in solver logs and exported files, it must be unique among the constraints of the Model.
+func (m *Model) AddNamedConstraint(name string, e *LinearExpression, t ConstraintType, rhs float64) (*Constraint, error) {
+	if _, exists := m.constraintsByName[name]; exists {
+		return nil, fmt.Errorf("%w: constraint %s", ErrDuplicateName, name)
+	}
+
+	lb, ub := constraintBounds(t, rhs)
+	return m.addConstraint(name, lb, ub, e), nil
+}
 
+// constraintBounds returns the bounds lb <= expression <= ub equivalent to expression t rhs.
+func constraintBounds(t ConstraintType, rhs float64) (lb, ub float64) {
 	switch t {
 	case LessThanOrEqual:
-		c = s.newConstraint(math.Inf(-1), rhs)
+		return math.Inf(-1), rhs
 	case Equal, "=":
-		c = s.newConstraint(rhs, rhs)
+		return rhs, rhs
 	case GreaterThanOrEqual:
-		c = s.newConstraint(rhs, math.Inf(1))
+		return rhs, math.Inf(1)
 
 	// In case "<" or ">" strings are directly passed to the function as ConstraintType
 	case ">", "<":
 		panic(fmt.Sprintf("Strict inequalities are not supported: %s", t))
 	default:
-		panic(fmt.Sprintf("Unknown cconstraint type: %s", t))
+		panic(fmt.Sprintf("Unknown constraint type: %s", t))
 	}
+}
 
-	for v, weight := range e.terms {
-		c.setCoefficient(v, weight)
-	}
+// addConstraint adds the constraint lb <= e <= ub to the Model, the constant of e is moved to the bounds.
+// A duplicate name, invalid bounds or coefficients are recorded as the Model error,
+// a duplicate constraint is then not indexed by name.
+func (m *Model) addConstraint(name string, lb, ub float64, e *LinearExpression) *Constraint {
+	m.checkNotSolving()
+	m.checkOwnership(e)
 
+	expr := e.Clone()
+	expr.constant = 0
+	c := &Constraint{
+		index:      len(m.constraints),
+		name:       name,
+		lowerBound: lb - e.constant,
+		upperBound: ub - e.constant,
+		expr:       expr,
+	}
+	m.constraints = append(m.constraints, c)
+	m.checkCoefficients("constraint "+c.label(), e)
+	m.checkBounds("constraint "+c.label(), c.lowerBound, c.upperBound)
+	if name != "" {
+		if _, exists := m.constraintsByName[name]; exists {
+			m.setErr(fmt.Errorf("%w: constraint %s", ErrDuplicateName, name))
+		} else {
+			m.constraintsByName[name] = c
+		}
+	}
 	return c
 }
+
+// Name returns the name of the constraint, empty for anonymous constraints.
+func (c *Constraint) Name() string { return c.name }
+
+// label returns the name of the constraint, or its index for anonymous constraints, for messages.
+func (c *Constraint) label() string {
+	if c.name != "" {
+		return c.name
+	}
+	return fmt.Sprintf("#%d", c.index)
+}
+
+// Index returns the position of the constraint in its Model.
+func (c *Constraint) Index() int { return c.index }
+
+// Lower returns the lower bound of the constraint, -Inf for a <= constraint.
+func (c *Constraint) Lower() float64 { return c.lowerBound }
+
+// Upper returns the upper bound of the constraint, +Inf for a >= constraint.
+func (c *Constraint) Upper() float64 { return c.upperBound }
+
+// Expression returns a copy of the linear expression of the constraint, its constant is always 0.
+func (c *Constraint) Expression() *LinearExpression { return c.expr.Clone() }
+
+// String returns the constraint in a readable form, e.g. "supply: x + y <= 100" or "#3: 2 <= x - y <= 5".
+func (c *Constraint) String() string {
+	return c.label() + ": " + c.relation()
+}
+
+// relation formats the constraint without its label, e.g. "x + y <= 3".
+func (c *Constraint) relation() string {
+	switch {
+	case c.lowerBound == c.upperBound:
+		return c.expr.String() + " == " + formatNumber(c.upperBound)
+	case math.IsInf(c.lowerBound, -1):
+		return c.expr.String() + " <= " + formatNumber(c.upperBound)
+	case math.IsInf(c.upperBound, 1):
+		return c.expr.String() + " >= " + formatNumber(c.lowerBound)
+	default:
+		return formatNumber(c.lowerBound) + " <= " + c.expr.String() + " <= " + formatNumber(c.upperBound)
+	}
+}
+
+// Activity returns the value of the constraint's linear expression, without its constant, in the most recent solution.
+func (c *Constraint) Activity() float64 { return c.activity }
+
+// Slack returns the distance between the activity and the closest bound of the constraint
+// in the most recent solution, 0 if the constraint is tight.
+func (c *Constraint) Slack() float64 {
+	return math.Min(c.activity-c.lowerBound, c.upperBound-c.activity)
+}
+
+// DualValue returns the dual value (shadow price) of the constraint in the most recent solution:
+// the change of the objective per unit increase of the constraint's right-hand side.
+// Dual values only exist for linear programs solved by an LP solver (e.g. GLOP), it is 0 otherwise.
+func (c *Constraint) DualValue() float64 { return c.dualValue }
+
+// BasisStatus returns the status of the constraint's slack variable in the simplex basis of the most recent solution.
+// Like DualValue, it is only available for linear programs solved by an LP solver.
+func (c *Constraint) BasisStatus() BasisStatus { return c.basisStatus }
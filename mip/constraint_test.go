@@ -0,0 +1,102 @@
+package mip
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConstraintSolution(t *testing.T) {
+	m := NewModel()
+	x := m.VarFloat("x", 0, 10)
+	y := m.VarFloat("y", 0, 10)
+	e := NewLinearExpression()
+	e.AddVar(x)
+	e.AddVar(y)
+	supply := m.AddConstraintExpr(e, LessThanOrEqual, 6)
+	demand := m.AddRangeConstraint(1, sumOf(x), 4)
+	m.SetObjective(e, Maximize)
+
+	s, b := newTestSolver(m)
+	b.duals = true
+	if _, err := s.Solve(0); err != nil {
+		t.Fatal(err)
+	}
+	if supply.Activity() != 6 || supply.Slack() != 0 || supply.DualValue() != 0.5 || supply.BasisStatus() != Basic {
+		t.Errorf("got activity %v, slack %v, dual value %v, basis status %v, want 6, 0, 0.5 and Basic",
+			supply.Activity(), supply.Slack(), supply.DualValue(), supply.BasisStatus())
+	}
+	if a := demand.Activity(); a < 1 || a > 4 || demand.Slack() != math.Min(a-1, 4-a) || demand.DualValue() != 1.5 {
+		t.Errorf("got activity %v, slack %v and dual value %v", a, demand.Slack(), demand.DualValue())
+	}
+}
+
+func TestConstraintSolutionWithoutDuals(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 3)
+	c := m.AddConstraintExpr(sumOf(x), GreaterThanOrEqual, 1)
+	s, _ := newTestSolver(m)
+	if _, err := s.Solve(0); err != nil {
+		t.Fatal(err)
+	}
+	if c.Activity() != 1 || c.Slack() != 0 || c.DualValue() != 0 || c.BasisStatus() != BasisFree {
+		t.Errorf("got activity %v, slack %v, dual value %v, basis status %v", c.Activity(), c.Slack(), c.DualValue(), c.BasisStatus())
+	}
+}
+
+func TestAddConstraintBetweenExpressions(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 10)
+	y := m.VarInt("y", 0, 10)
+	lhs := sumOf(x)
+	lhs.AddConstant(3)
+	rhs := NewLinearExpression()
+	rhs.AddTerm(y, 2)
+	rhs.AddConstant(1)
+
+	tests := []struct {
+		t    ConstraintType
+		want string
+	}{
+		{LessThanOrEqual, "#0: x - 2 y <= -2"},
+		{GreaterThanOrEqual, "#1: x - 2 y >= -2"},
+		{Equal, "#2: x - 2 y == -2"},
+	}
+	for _, test := range tests {
+		if c := m.AddConstraint(lhs, test.t, rhs); c.String() != test.want {
+			t.Errorf("got %v, want %s", c, test.want)
+		}
+	}
+	if lhs.String() != "x + 3" || rhs.String() != "2 y + 1" {
+		t.Errorf("the sides changed to %s and %s", lhs, rhs)
+	}
+}
+
+func TestAddRangeConstraint(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 10)
+	e := sumOf(x)
+	e.AddConstant(1)
+	c := m.AddRangeConstraint(2, e, 5)
+	if c.String() != "#0: 1 <= x <= 4" {
+		t.Errorf("got %v", c)
+	}
+	if c := m.AddRangeConstraint(math.Inf(-1), e, 5); !math.IsInf(c.Lower(), -1) || c.Upper() != 4 {
+		t.Errorf("got %v", c)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic for crossed bounds")
+		}
+	}()
+	m.AddRangeConstraint(5, e, 2)
+}
+
+// sumOf returns the expression adding up the variables.
+func sumOf(vars ...*Variable) *LinearExpression {
+	e := NewLinearExpression()
+	for _, v := range vars {
+		e.AddVar(v)
+	}
+	return e
+}
@@ -0,0 +1,494 @@
+package mip
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// This file writes models in the MPS and CPLEX LP text formats, e.g. to debug them or to solve them with other solvers.
+// Variables and constraints are written in index order, so the same model always produces the same file.
+
+const (
+	mpsObjectiveName = "OBJ"
+	lpObjectiveName  = "obj"
+)
+
+// WriteMPS writes the Model in free MPS format.
+// Variables and constraints keep their names if all of them are valid MPS names and unique,
+// otherwise they are named after their indices: C0, C1, ... for variables, R0, R1, ... for constraints.
+// Indicator constraints are written as rows I0, I1, ... listed in the INDICATORS section, and special ordered sets
+// as sets s0, s1, ... of the SOS section, extensions of CPLEX and Gurobi.
+func (m *Model) WriteMPS(w io.Writer) error {
+	return m.writeMPS(w, false)
+}
+
+// WriteFixedMPS writes the Model in fixed MPS format, where names are limited to 8 characters
+// and numbers to 12 characters, which may lose precision. Naming follows the rules of WriteMPS.
+func (m *Model) WriteFixedMPS(w io.Writer) error {
+	return m.writeMPS(w, true)
+}
+
+// WriteLP writes the Model in CPLEX LP format. Naming follows the rules of WriteMPS.
+// LP has no two-sided constraints: ranged constraints are written as two constraints suffixed with _lb and _ub,
+// and free constraints (without finite bounds) are omitted. Indicator constraints are written as "b = 1 -> ...",
+// special ordered sets in the SOS section as "s0: S1:: x:1 y:2".
+func (m *Model) WriteLP(w io.Writer) error {
+	cols, rows := m.exportNames(validLPName, lpObjectiveName)
+	indicatorRows := indicatorNames(len(m.indicators), rows, lpObjectiveName)
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, `\ written by gomip`)
+	if m.sense == Maximize {
+		fmt.Fprintln(bw, "Maximize")
+	} else {
+		fmt.Fprintln(bw, "Minimize")
+	}
+	writeLPExpression(bw, lpObjectiveName+":", m.fullObjective(), cols)
+	fmt.Fprintln(bw)
+
+	fmt.Fprintln(bw, "Subject To")
+	for _, c := range m.constraints {
+		writeLPConstraint(bw, rows[c.index], "", c, cols)
+	}
+	for i, ic := range m.indicators {
+		condition := fmt.Sprintf(" %s = %d ->", cols[ic.indicator.index], ic.activeValue())
+		writeLPConstraint(bw, indicatorRows[i], condition, ic.row(), cols)
+	}
+
+	fmt.Fprintln(bw, "Bounds")
+	var generals, binaries []string
+	for _, v := range m.variables {
+		name, lb, ub := cols[v.index], v.lowerBound, v.upperBound
+		if v.integer && lb == 0 && ub == 1 {
+			binaries = append(binaries, name)
+			continue
+		}
+		if v.integer {
+			generals = append(generals, name)
+		}
+
+		switch {
+		case lb == ub:
+			fmt.Fprintf(bw, " %s = %s\n", name, formatNumber(lb))
+		case math.IsInf(lb, -1) && math.IsInf(ub, 1):
+			fmt.Fprintf(bw, " %s free\n", name)
+		case math.IsInf(ub, 1):
+			if lb != 0 {
+				fmt.Fprintf(bw, " %s >= %s\n", name, formatNumber(lb))
+			}
+		default:
+			fmt.Fprintf(bw, " %s <= %s <= %s\n", formatLPBound(lb), name, formatNumber(ub))
+		}
+	}
+
+	writeLPNames(bw, "Generals", generals)
+	writeLPNames(bw, "Binaries", binaries)
+	if len(m.sos) > 0 {
+		fmt.Fprintln(bw, "SOS")
+		for _, sos := range m.sos {
+			line := fmt.Sprintf(" s%d: S%d::", sos.index, sos.sosType)
+			for i, v := range sos.vars {
+				term := " " + cols[v.index] + ":" + formatNumber(sos.weights[i])
+				if len(line)+len(term) > maxLPLineLength {
+					fmt.Fprintln(bw, line)
+					line = ""
+				}
+				line += term
+			}
+			fmt.Fprintln(bw, line)
+		}
+	}
+	fmt.Fprintln(bw, "End")
+	return bw.Flush()
+}
+
+// maxLPLineLength keeps lines well below the 510 characters accepted by CPLEX.
+const maxLPLineLength = 250
+
+// writeLPConstraint writes the constraint under the given name, the condition is written before the expression.
+func writeLPConstraint(w *bufio.Writer, name, condition string, c *Constraint, cols []string) {
+	lb, ub := c.lowerBound, c.upperBound
+	switch {
+	case math.IsInf(lb, -1) && math.IsInf(ub, 1):
+		fmt.Fprintf(w, "\\ %s is free\n", name)
+	case lb == ub:
+		writeLPExpression(w, name+":"+condition, c.expr, cols)
+		fmt.Fprintf(w, " = %s\n", formatNumber(ub))
+	case math.IsInf(lb, -1):
+		writeLPExpression(w, name+":"+condition, c.expr, cols)
+		fmt.Fprintf(w, " <= %s\n", formatNumber(ub))
+	case math.IsInf(ub, 1):
+		writeLPExpression(w, name+":"+condition, c.expr, cols)
+		fmt.Fprintf(w, " >= %s\n", formatNumber(lb))
+	default:
+		writeLPExpression(w, name+"_lb:"+condition, c.expr, cols)
+		fmt.Fprintf(w, " >= %s\n", formatNumber(lb))
+		writeLPExpression(w, name+"_ub:"+condition, c.expr, cols)
+		fmt.Fprintf(w, " <= %s\n", formatNumber(ub))
+	}
+}
+
+// writeLPExpression writes "head a1 x1 + a2 x2 ...", terms sorted by variable index, without the trailing newline.
+func writeLPExpression(w *bufio.Writer, head string, e *LinearExpression, cols []string) {
+	line := " " + head
+	if len(e.terms) == 0 && len(cols) > 0 {
+		line += " 0 " + cols[0] // some readers require at least one term
+	}
+
+	for i, v := range e.Vars() {
+		coeff := e.terms[v]
+		term := " + " + formatNumber(coeff) + " " + cols[v.index]
+		if coeff < 0 {
+			term = " - " + formatNumber(-coeff) + " " + cols[v.index]
+		} else if i == 0 {
+			term = " " + formatNumber(coeff) + " " + cols[v.index]
+		}
+		if len(line)+len(term) > maxLPLineLength {
+			fmt.Fprintln(w, line)
+			line = ""
+		}
+		line += term
+	}
+	if e.constant > 0 {
+		line += " + " + formatNumber(e.constant)
+	} else if e.constant < 0 {
+		line += " - " + formatNumber(-e.constant)
+	}
+	fmt.Fprint(w, line)
+}
+
+func writeLPNames(w *bufio.Writer, section string, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	fmt.Fprintln(w, section)
+	line := ""
+	for _, name := range names {
+		if len(line)+len(name) > maxLPLineLength {
+			fmt.Fprintln(w, line)
+			line = ""
+		}
+		line += " " + name
+	}
+	fmt.Fprintln(w, line)
+}
+
+func formatLPBound(x float64) string {
+	if math.IsInf(x, -1) {
+		return "-inf"
+	}
+	return formatNumber(x)
+}
+
+func (m *Model) writeMPS(w io.Writer, fixed bool) error {
+	valid := validFreeMPSName
+	format := formatNumber
+	if fixed {
+		valid = validFixedMPSName
+		format = formatFixedMPSNumber
+	}
+	cols, rows := m.exportNames(valid, mpsObjectiveName)
+
+	// indicator constraints are rows of their own, listed again in the INDICATORS section
+	allRows := m.constraints
+	if len(m.indicators) > 0 {
+		allRows = slices.Clone(m.constraints)
+		for _, ic := range m.indicators {
+			row := ic.row()
+			row.index = len(allRows)
+			allRows = append(allRows, row)
+		}
+		rows = append(slices.Clone(rows), indicatorNames(len(m.indicators), rows, mpsObjectiveName)...)
+	}
+
+	bw := bufio.NewWriter(w)
+	line := func(fields ...string) {
+		if fixed {
+			writeFixedMPSLine(bw, fields)
+		} else {
+			writeFreeMPSLine(bw, fields)
+		}
+	}
+
+	fmt.Fprintln(bw, "NAME")
+	if m.sense == Maximize {
+		fmt.Fprintln(bw, "OBJSENSE")
+		fmt.Fprintln(bw, "    MAX")
+	}
+
+	fmt.Fprintln(bw, "ROWS")
+	line("N", mpsObjectiveName)
+	for _, c := range allRows {
+		line(mpsRowType(c), rows[c.index])
+	}
+
+	// MPS is column oriented: gather the coefficients of each variable, sorted by row
+	type entry struct {
+		row   int
+		coeff float64
+	}
+	columns := make([][]entry, len(m.variables))
+	for _, c := range allRows {
+		for v, coeff := range c.expr.terms {
+			columns[v.index] = append(columns[v.index], entry{c.index, coeff})
+		}
+	}
+
+	objective := m.fullObjective()
+	fmt.Fprintln(bw, "COLUMNS")
+	inIntegerBlock := false
+	for _, v := range m.variables {
+		if v.integer != inIntegerBlock {
+			marker := "'INTORG'"
+			if inIntegerBlock {
+				marker = "'INTEND'"
+			}
+			line("", "MARKER", "'MARKER'", "", marker)
+			inIntegerBlock = v.integer
+		}
+
+		name := cols[v.index]
+		coeff, inObjective := objective.terms[v]
+		column := columns[v.index]
+		if inObjective || len(column) == 0 { // every column must appear in the COLUMNS section
+			line("", name, mpsObjectiveName, format(coeff))
+		}
+		sort.Slice(column, func(i, j int) bool { return column[i].row < column[j].row })
+		for _, e := range column {
+			line("", name, rows[e.row], format(e.coeff))
+		}
+	}
+	if inIntegerBlock {
+		line("", "MARKER", "'MARKER'", "", "'INTEND'")
+	}
+
+	fmt.Fprintln(bw, "RHS")
+	if objective.constant != 0 {
+		line("", "RHS", mpsObjectiveName, format(-objective.constant))
+	}
+	for _, c := range allRows {
+		if rhs := mpsRHS(c); rhs != 0 {
+			line("", "RHS", rows[c.index], format(rhs))
+		}
+	}
+
+	fmt.Fprintln(bw, "RANGES")
+	for _, c := range allRows {
+		if c.lowerBound != c.upperBound && !math.IsInf(c.lowerBound, -1) && !math.IsInf(c.upperBound, 1) {
+			line("", "RNG", rows[c.index], format(c.upperBound-c.lowerBound))
+		}
+	}
+
+	fmt.Fprintln(bw, "BOUNDS")
+	for _, v := range m.variables {
+		name, lb, ub := cols[v.index], v.lowerBound, v.upperBound
+		switch {
+		case v.integer && lb == 0 && ub == 1:
+			line("BV", "BND", name)
+		case lb == ub:
+			line("FX", "BND", name, format(lb))
+		case math.IsInf(lb, -1) && math.IsInf(ub, 1):
+			line("FR", "BND", name)
+		default:
+			if math.IsInf(lb, -1) {
+				line("MI", "BND", name)
+			} else if lb != 0 || ub < 0 {
+				// some readers make a variable with a negative upper bound and no lower bound unbounded below
+				line("LO", "BND", name, format(lb))
+			}
+			if !math.IsInf(ub, 1) {
+				line("UP", "BND", name, format(ub))
+			} else if v.integer {
+				line("PL", "BND", name) // some readers give integer variables an upper bound of 1 by default
+			}
+		}
+	}
+
+	if len(m.indicators) > 0 {
+		fmt.Fprintln(bw, "INDICATORS")
+		for i, ic := range m.indicators {
+			line("IF", rows[len(m.constraints)+i], cols[ic.indicator.index], strconv.Itoa(ic.activeValue()))
+		}
+	}
+
+	if len(m.sos) > 0 {
+		fmt.Fprintln(bw, "SOS")
+		for _, sos := range m.sos {
+			line("S"+strconv.Itoa(int(sos.sosType)), "SOS", "s"+strconv.Itoa(sos.index))
+			for i, v := range sos.vars {
+				line("", cols[v.index], format(sos.weights[i]))
+			}
+		}
+	}
+
+	fmt.Fprintln(bw, "ENDATA")
+	return bw.Flush()
+}
+
+// mpsRowType returns the MPS type of a constraint: L (<=), G (>=), E (==) or N (free).
+// Ranged constraints are written as L rows with a range.
+func mpsRowType(c *Constraint) string {
+	switch {
+	case c.lowerBound == c.upperBound:
+		return "E"
+	case !math.IsInf(c.upperBound, 1):
+		return "L"
+	case !math.IsInf(c.lowerBound, -1):
+		return "G"
+	default:
+		return "N"
+	}
+}
+
+func mpsRHS(c *Constraint) float64 {
+	switch mpsRowType(c) {
+	case "E", "L":
+		return c.upperBound
+	case "G":
+		return c.lowerBound
+	default:
+		return 0
+	}
+}
+
+// writeFreeMPSLine writes the non-empty fields separated by spaces, indented like the fixed MPS format.
+func writeFreeMPSLine(w *bufio.Writer, fields []string) {
+	indent := " "
+	if fields[0] == "" {
+		indent = "    "
+	}
+	nonEmpty := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field != "" {
+			nonEmpty = append(nonEmpty, field)
+		}
+	}
+	fmt.Fprintln(w, indent+strings.Join(nonEmpty, " "))
+}
+
+// writeFixedMPSLine writes the fields at the columns 2, 5, 15, 25, 40 and 50 of the fixed MPS format.
+func writeFixedMPSLine(w *bufio.Writer, fields []string) {
+	widths := []int{2, 8, 8, 12, 8, 12}
+	separators := []string{" ", " ", "  ", "  ", "   ", "  "}
+
+	var sb strings.Builder
+	for i, field := range fields {
+		sb.WriteString(separators[i])
+		sb.WriteString(fmt.Sprintf("%-*s", widths[i], field))
+	}
+	fmt.Fprintln(w, strings.TrimRight(sb.String(), " "))
+}
+
+// exportNames returns the names under which the variables and constraints are written.
+// The own names of each kind are kept if they are all valid and unique (also with respect to the objective name),
+// otherwise names are generated from the indices.
+func (m *Model) exportNames(valid func(string) bool, objectiveName string) (cols, rows []string) {
+	cols = make([]string, len(m.variables))
+	for i, v := range m.variables {
+		cols[i] = v.name
+	}
+	rows = make([]string, len(m.constraints))
+	for i, c := range m.constraints {
+		rows[i] = c.name
+	}
+
+	return keepOrGenerateNames(cols, "C", "", valid), keepOrGenerateNames(rows, "R", objectiveName, valid)
+}
+
+// indicatorNames returns the names of the rows of the indicator constraints: I0, I1, ...
+// made distinct from the names of the other rows.
+func indicatorNames(n int, rows []string, objectiveName string) []string {
+	taken := map[string]bool{objectiveName: true}
+	for _, row := range rows {
+		taken[row] = true
+	}
+	names := make([]string, n)
+	for i := range names {
+		name := "I" + strconv.Itoa(i)
+		for taken[name] {
+			name += "_"
+		}
+		taken[name] = true
+		names[i] = name
+	}
+	return names
+}
+
+func keepOrGenerateNames(names []string, prefix, reserved string, valid func(string) bool) []string {
+	seen := map[string]bool{reserved: true}
+	keep := true
+	for _, name := range names {
+		if !valid(name) || seen[name] {
+			keep = false
+			break
+		}
+		seen[name] = true
+	}
+	if keep {
+		return names
+	}
+
+	generated := make([]string, len(names))
+	for i := range names {
+		generated[i] = prefix + strconv.Itoa(i)
+	}
+	return generated
+}
+
+func validFreeMPSName(name string) bool {
+	return name != "" && !strings.ContainsFunc(name, unicode.IsSpace) && name[0] != '*' && name[0] != '$'
+}
+
+func validFixedMPSName(name string) bool {
+	return validFreeMPSName(name) && len(name) <= 8
+}
+
+// validLPName follows the CPLEX LP rules: no spaces nor operators, not starting with a digit or a period,
+// and not starting like an exponent or reading like a keyword.
+func validLPName(name string) bool {
+	if name == "" || len(name) > 255 {
+		return false
+	}
+	for _, r := range name {
+		isLetterOrDigit := r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+		if !isLetterOrDigit && !strings.ContainsRune(`!"#$%&()/,.;?@_'{}|~`, r) {
+			return false
+		}
+	}
+	if unicode.IsDigit(rune(name[0])) || name[0] == '.' {
+		return false
+	}
+	if (name[0] == 'e' || name[0] == 'E') && (len(name) == 1 || unicode.IsDigit(rune(name[1]))) {
+		return false
+	}
+	switch strings.ToLower(name) {
+	case "inf", "infinity", "free":
+		return false
+	case "maximize", "maximise", "maximum", "max", "minimize", "minimise", "minimum", "min",
+		"subject", "such", "st", "st.", "s.t.", "bounds", "bound", "general", "generals", "gen",
+		"binary", "binaries", "bin", "sos", "semi", "semis", "end":
+		return false // the section keywords of lpParser.section
+	}
+	return true
+}
+
+// formatNumber returns the shortest representation of x that parses back to x.
+func formatNumber(x float64) string {
+	return strconv.FormatFloat(x, 'g', -1, 64)
+}
+
+// formatFixedMPSNumber returns the most precise representation of x fitting the 12 characters of a fixed MPS field.
+func formatFixedMPSNumber(x float64) string {
+	s := formatNumber(x)
+	for precision := 12; len(s) > 12 && precision > 0; precision-- {
+		s = strconv.FormatFloat(x, 'g', precision, 64)
+	}
+	return s
+}
@@ -0,0 +1,148 @@
+package mip
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// exportTestModel returns a small model with every kind of variable and constraint.
+func exportTestModel() *Model {
+	m := NewModel()
+	x := m.VarInt("x", 0, 10)
+	y := m.VarFloat("y", -5, 5)
+	z := m.VarBool("z")
+	f := m.VarFloat("f", math.Inf(-1), math.Inf(1))
+	capacity := NewLinearExpression()
+	capacity.AddVar(x)
+	capacity.AddTerm(y, 2)
+	m.AddNamedConstraint("cap", capacity, LessThanOrEqual, 8)
+	difference := NewLinearExpression()
+	difference.AddVar(x)
+	difference.AddTerm(y, -1)
+	difference.AddVar(f)
+	m.addConstraint("rng", 1, 4, difference)
+	m.AddNamedConstraint("eq", sumOf(x, z), Equal, 3)
+	objective := NewLinearExpression()
+	objective.AddTerm(x, 3)
+	objective.AddTerm(y, 2)
+	objective.AddTerm(z, -1)
+	objective.AddConstant(1)
+	m.SetObjective(objective, Maximize)
+	return m
+}
+
+func TestWriteLP(t *testing.T) {
+	want := `\ written by gomip
+Maximize
+ obj: 3 x + 2 y - 1 z + 1
+Subject To
+ cap: 1 x + 2 y <= 8
+ rng_lb: 1 x - 1 y + 1 f >= 1
+ rng_ub: 1 x - 1 y + 1 f <= 4
+ eq: 1 x + 1 z = 3
+Bounds
+ 0 <= x <= 10
+ -5 <= y <= 5
+ f free
+Generals
+ x
+Binaries
+ z
+End
+`
+	var sb strings.Builder
+	if err := exportTestModel().WriteLP(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", sb.String(), want)
+	}
+}
+
+func TestWriteMPS(t *testing.T) {
+	want := `NAME
+OBJSENSE
+    MAX
+ROWS
+ N OBJ
+ L cap
+ L rng
+ E eq
+COLUMNS
+    MARKER 'MARKER' 'INTORG'
+    x OBJ 3
+    x cap 1
+    x rng 1
+    x eq 1
+    MARKER 'MARKER' 'INTEND'
+    y OBJ 2
+    y cap 2
+    y rng -1
+    MARKER 'MARKER' 'INTORG'
+    z OBJ -1
+    z eq 1
+    MARKER 'MARKER' 'INTEND'
+    f rng 1
+RHS
+    RHS OBJ -1
+    RHS cap 8
+    RHS rng 4
+    RHS eq 3
+RANGES
+    RNG rng 3
+BOUNDS
+ UP BND x 10
+ LO BND y -5
+ UP BND y 5
+ BV BND z
+ FR BND f
+ENDATA
+`
+	var sb strings.Builder
+	if err := exportTestModel().WriteMPS(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", sb.String(), want)
+	}
+}
+
+func TestWriteFixedMPS(t *testing.T) {
+	m := exportTestModel()
+	m.VarFloat("long_name_x", 0, 1)
+	var sb strings.Builder
+	if err := m.WriteFixedMPS(&sb); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"\n    C0        OBJ       3\n", "\n UP BND       C1        5\n", "\n    RNG       rng       3\n"} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("missing %q in\n%s", want, sb.String())
+		}
+	}
+}
+
+func TestExportNames(t *testing.T) {
+	m := NewModel()
+	m.VarBool("a b")
+	m.VarBool("c")
+	m.AddConstraintExpr(sumOf(m.Variables()...), LessThanOrEqual, 1)
+	cols, rows := m.exportNames(validLPName, lpObjectiveName)
+	if strings.Join(cols, ",") != "C0,C1" || strings.Join(rows, ",") != "R0" {
+		t.Errorf("got %v and %v, want generated names", cols, rows)
+	}
+
+	m = NewModel()
+	m.VarBool("a")
+	m.AddNamedConstraint(lpObjectiveName, sumOf(m.Variables()...), LessThanOrEqual, 1)
+	cols, rows = m.exportNames(validLPName, lpObjectiveName)
+	if strings.Join(cols, ",") != "a" || strings.Join(rows, ",") != "R0" {
+		t.Errorf("got %v and %v, want the row named after the objective to be renamed", cols, rows)
+	}
+
+	for _, name := range []string{"", "2x", "e1", "inf", "x y", "x+y"} {
+		if validLPName(name) {
+			t.Errorf("%q is a valid LP name", name)
+		}
+	}
+}
@@ -1,10 +1,18 @@
 package mip
 
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
 // LinearExpression represents a linear expression, in the form of:
-// a1*x1 + a2*x2 + ... + a_n*x_n
-// where a1, a2, ..., a_n are coefficients, and x1, x2, ..., x_n are variables.
+// a1*x1 + a2*x2 + ... + a_n*x_n + c
+// where a1, a2, ..., a_n are coefficients, x1, x2, ..., x_n are variables, and c is a constant.
+// Terms are always visited in variable index order (see Vars), so the same code builds byte-identical models.
 type LinearExpression struct {
-	terms map[*Variable]float64 // Variable to their corresponding coefficients
+	terms    map[*Variable]float64 // Variable to their corresponding coefficients
+	constant float64
 }
 
 // NewLinearExpression creates an empty linear expression.
@@ -16,16 +24,144 @@ func NewLinearExpression() *LinearExpression {
 
 // AddTerm adds a new weighted term to the linear expression.
 // i.e. (2 * x + 3 * y).AddTerm(z, 4) => 2 * x + 3 * y + 4 * z
+// A variable whose coefficient becomes 0 is removed from the expression.
 func (e *LinearExpression) AddTerm(v *Variable, weight float64) {
-	e.terms[v] = e.terms[v] + weight // if v is not in the map, e.terms[v] will be initially zero
+	if coeff := e.terms[v] + weight; coeff != 0 { // if v is not in the map, e.terms[v] will be initially zero
+		e.terms[v] = coeff
+	} else {
+		delete(e.terms, v)
+	}
 }
 
 func (e *LinearExpression) AddVar(v *Variable) {
-	e.terms[v] += 1
+	e.AddTerm(v, 1)
+}
+
+// AddConstant adds a constant to the linear expression.
+// i.e. (2 * x + 3).AddConstant(4) => 2 * x + 7
+func (e *LinearExpression) AddConstant(c float64) {
+	e.constant += c
 }
 
 func (e *LinearExpression) AddExpr(other *LinearExpression) {
 	for exprVariable, weight := range other.terms {
 		e.AddTerm(exprVariable, weight)
 	}
+	e.constant += other.constant
+}
+
+// Sub subtracts another expression from the linear expression.
+// i.e. (2 * x + 3 * y).Sub(x + 1) => x + 3 * y - 1
+func (e *LinearExpression) Sub(other *LinearExpression) {
+	for exprVariable, weight := range other.terms {
+		e.AddTerm(exprVariable, -weight)
+	}
+	e.constant -= other.constant
+}
+
+// Scale multiplies all the coefficients and the constant of the linear expression by a factor.
+// i.e. (2 * x + 3).Scale(2) => 4 * x + 6
+// Scaling by 0 removes all the variables.
+func (e *LinearExpression) Scale(factor float64) {
+	if factor == 0 {
+		clear(e.terms)
+	}
+	for v := range e.terms {
+		e.terms[v] *= factor
+	}
+	e.constant *= factor
+}
+
+// Neg negates the linear expression, i.e. (2 * x + 3).Neg() => -2 * x - 3
+func (e *LinearExpression) Neg() {
+	e.Scale(-1)
+}
+
+// Clone returns a copy of the linear expression, referring to the same variables.
+func (e *LinearExpression) Clone() *LinearExpression {
+	cp := NewLinearExpression()
+	cp.AddExpr(e)
+	return cp
+}
+
+// Coefficient returns the coefficient of a variable in the linear expression, 0 if it does not appear.
+func (e *LinearExpression) Coefficient(v *Variable) float64 {
+	return e.terms[v]
+}
+
+// Constant returns the constant of the linear expression.
+func (e *LinearExpression) Constant() float64 {
+	return e.constant
+}
+
+// Vars returns the variables appearing in the linear expression, sorted by index.
+func (e *LinearExpression) Vars() []*Variable {
+	vars := make([]*Variable, 0, len(e.terms))
+	for v := range e.terms {
+		vars = append(vars, v)
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].index < vars[j].index })
+	return vars
+}
+
+// Eval returns the value of the linear expression for the given variable values, missing variables count as 0.
+func (e *LinearExpression) Eval(values map[*Variable]float64) float64 {
+	sum := e.constant
+	for _, v := range e.Vars() {
+		sum += e.terms[v] * values[v]
+	}
+	return sum
+}
+
+// String returns the linear expression in a readable form, e.g. "2 x - y + 3", with the terms in Vars order.
+// Anonymous variables are shown by index, e.g. "#3".
+func (e *LinearExpression) String() string {
+	var sb strings.Builder
+	for _, v := range e.Vars() {
+		writeTerm(&sb, e.terms[v], v.label())
+	}
+	if e.constant != 0 || sb.Len() == 0 {
+		writeTerm(&sb, e.constant, "")
+	}
+	return sb.String()
+}
+
+// writeTerm appends "+ coeff name" to sb, omitting the sign of a first positive term and a coefficient of 1.
+func writeTerm(sb *strings.Builder, coeff float64, name string) {
+	switch {
+	case coeff < 0 && sb.Len() == 0:
+		sb.WriteString("-")
+	case coeff < 0:
+		sb.WriteString(" - ")
+	case sb.Len() > 0:
+		sb.WriteString(" + ")
+	}
+	coeff = math.Abs(coeff)
+	if coeff != 1 || name == "" {
+		sb.WriteString(formatNumber(coeff))
+		if name != "" {
+			sb.WriteString(" ")
+		}
+	}
+	sb.WriteString(name)
+}
+
+// solutionValue returns the value of the expression in the most recent solution.
+func (e *LinearExpression) solutionValue() float64 {
+	sum := e.constant
+	for _, v := range e.Vars() {
+		sum += e.terms[v] * v.value
+	}
+	return sum
+}
+
+// remap returns a copy of the expression where each variable is replaced by the variable
+// at the same index in vars. It is used to carry expressions over to a copied Model.
+func (e *LinearExpression) remap(vars []*Variable) *LinearExpression {
+	cp := NewLinearExpression()
+	for _, v := range e.Vars() {
+		cp.AddTerm(vars[v.index], e.terms[v])
+	}
+	cp.constant = e.constant
+	return cp
 }
@@ -0,0 +1,122 @@
+package mip
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLinearExpressionArithmetic(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 10)
+	y := m.VarInt("y", 0, 10)
+	z := m.VarInt("", 0, 10)
+
+	e := NewLinearExpression()
+	e.AddTerm(x, 2)
+	e.AddTerm(y, 3)
+	e.AddConstant(1)
+	other := sumOf(x, z)
+	other.AddConstant(4)
+
+	cp := e.Clone()
+	e.Sub(other)
+	if e.String() != "x + 3 y - #2 - 3" || cp.String() != "2 x + 3 y + 1" {
+		t.Errorf("got %s and the clone %s", e, cp)
+	}
+	e.Scale(2)
+	if e.Coefficient(y) != 6 || e.Coefficient(z) != -2 || e.Constant() != -6 {
+		t.Errorf("got %s after scaling by 2", e)
+	}
+	e.Neg()
+	if e.String() != "-2 x - 6 y + 2 #2 + 6" {
+		t.Errorf("got %s after negating", e)
+	}
+	if got := e.Eval(map[*Variable]float64{x: 1, y: 0.5}); got != 1 {
+		t.Errorf("got %v, want 1", got)
+	}
+	if NewLinearExpression().String() != "0" || NewLinearExpression().Eval(nil) != 0 {
+		t.Error("unexpected empty expression")
+	}
+
+	e.AddTerm(x, 2)
+	e.AddVar(z)
+	if e.String() != "-6 y + 3 #2 + 6" || slices.Contains(e.Vars(), x) {
+		t.Errorf("got %s with the variables %v, want x removed", e, e.Vars())
+	}
+	e.Scale(0)
+	if e.String() != "0" || len(e.Vars()) != 0 {
+		t.Errorf("got %s with the variables %v after scaling by 0", e, e.Vars())
+	}
+}
+
+func TestLinearExpressionConstantIsFolded(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 10)
+	e := sumOf(x)
+	e.AddConstant(2)
+	c := m.AddConstraintExpr(e, LessThanOrEqual, 5)
+	if c.Upper() != 3 || c.Expression().Constant() != 0 {
+		t.Errorf("got %v, want x <= 3", c)
+	}
+	e.AddConstant(8)
+	m.SetObjective(e, Maximize)
+
+	s, b := newTestSolver(m)
+	if _, err := s.Solve(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if b.offset != 10 || s.ObjectiveValue() != 13 || c.Activity() != 3 {
+		t.Errorf("got offset %v, objective %v and activity %v, want 10, 13 and 3", b.offset, s.ObjectiveValue(), c.Activity())
+	}
+}
+
+// reversedModel returns a Model whose expressions get their terms in decreasing variable index order.
+func reversedModel() *Model {
+	m := NewModel()
+	vars := make([]*Variable, 8)
+	for i := range vars {
+		vars[i] = m.VarInt(fmt.Sprintf("x%d", i), 0, 1)
+	}
+	e := NewLinearExpression()
+	for i := len(vars) - 1; i >= 0; i-- {
+		e.AddTerm(vars[i], float64(i+1))
+	}
+	m.AddConstraintExpr(e, LessThanOrEqual, 2)
+	m.SetObjective(e, Maximize)
+	return m
+}
+
+func TestLinearExpressionOrder(t *testing.T) {
+	m := reversedModel()
+	vars := m.Constraints()[0].Expression().Vars()
+	if !slices.IsSortedFunc(vars, func(a, b *Variable) int { return a.Index() - b.Index() }) || len(vars) != 8 {
+		t.Errorf("got variables %v, want them by index", vars)
+	}
+
+	s, b := newTestSolver(m)
+	b.hints = true
+	hint := map[*Variable]float64{}
+	for _, v := range m.Variables() {
+		hint[v] = 0
+	}
+	s.SetHint(hint)
+	if _, err := s.Solve(0); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.IsSorted(b.rows[0].vars) || !slices.IsSorted(b.hintVars) || len(b.hintVars) != 8 {
+		t.Errorf("got row variables %v and hint variables %v, want them by index", b.rows[0].vars, b.hintVars)
+	}
+
+	first, err := m.ExportProto(ProtoBinary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 5 {
+		if data, _ := reversedModel().ExportProto(ProtoBinary); !bytes.Equal(data, first) {
+			t.Fatal("the same model gives different bytes")
+		}
+	}
+}
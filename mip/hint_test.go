@@ -0,0 +1,56 @@
+package mip
+
+import "testing"
+
+func TestSolveWithHint(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 5)
+	y := m.VarInt("y", 0, 5)
+	e := NewLinearExpression()
+	e.AddVar(x)
+	e.AddVar(y)
+	m.AddConstraintExpr(e, LessThanOrEqual, 4)
+	s, b := newTestSolver(m)
+	b.hints = true
+
+	tests := []struct {
+		hint map[*Variable]float64
+		want bool
+	}{
+		{map[*Variable]float64{x: 1, y: 3}, true},
+		{map[*Variable]float64{x: 3, y: 3}, false}, // infeasible
+		{map[*Variable]float64{y: 2}, false},       // partial
+		{nil, false},
+	}
+	for _, test := range tests {
+		s.SetHint(test.hint)
+		result, err := s.Solve(0)
+		if err != nil || result.HintAccepted != test.want {
+			t.Errorf("hint %v: got HintAccepted = %v, %v, want %v", test.hint, result.HintAccepted, err, test.want)
+		}
+		if len(b.hintVars) != len(test.hint) {
+			t.Errorf("hint %v: %d values passed to the backend", test.hint, len(b.hintVars))
+		}
+	}
+
+	s.SetHint(map[*Variable]float64{y: 2})
+	s.Solve(0)
+	if len(b.hintVars) != 1 || b.hintVars[0] != 1 || b.hintValues[0] != 2 {
+		t.Errorf("got %v = %v, want the column of y = 2", b.hintVars, b.hintValues)
+	}
+}
+
+func TestHintIgnoredByBackend(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 5)
+	s, b := newTestSolver(m)
+	s.SetHint(map[*Variable]float64{x: 1})
+	if result, err := s.Solve(0); err != nil || result.HintAccepted || len(b.hintVars) != 1 {
+		t.Errorf("got HintAccepted = %v, %v, want false for a backend ignoring hints", result.HintAccepted, err)
+	}
+	s = NewSolverWithBackend(m, struct{ Backend }{&testBackend{}})
+	s.SetHint(map[*Variable]float64{x: 1})
+	if result, err := s.Solve(0); err != nil || result.HintAccepted {
+		t.Errorf("got HintAccepted = %v, %v, want false for a backend without hints", result.HintAccepted, err)
+	}
+}
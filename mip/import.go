@@ -0,0 +1,1101 @@
+package mip
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// This file reads models from the MPS and CPLEX LP text formats.
+// Variables and constraints keep the names they have in the file, they can be retrieved with
+// Model.VariableByName and Model.ConstraintByName.
+
+// ParseError is returned by ReadMPS and ReadLP for malformed input.
+// It wraps the Model error for an invalid model, e.g. a lower bound greater than an upper bound,
+// which then matches ErrModelInvalid with errors.Is.
+type ParseError struct {
+	Line int // 1-based line number of the error
+	Msg  string
+
+	err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+func (e *ParseError) Unwrap() error { return e.err }
+
+func parseErrorf(line int, format string, args ...any) *ParseError {
+	return &ParseError{Line: line, Msg: fmt.Sprintf(format, args...)}
+}
+
+// infinityThreshold is the magnitude from which numbers are read as infinite, as is customary in MPS and LP files.
+const infinityThreshold = 1e30
+
+// modelBuilder gathers variables and constraints while a file is parsed, the Model is built once the file is read,
+// since bounds and right-hand sides may come after the coefficients.
+type modelBuilder struct {
+	vars       []*parsedVariable
+	varsByName map[string]*parsedVariable
+	rows       []*parsedRow
+	rowsByName map[string]*parsedRow
+	sos        []*parsedSOS
+	objective  map[*parsedVariable]float64
+	offset     float64
+	sense      OptimizationType
+
+	objectiveLine int // last line defining the objective, for errors
+}
+
+type parsedVariable struct {
+	name       string
+	index      int
+	lowerBound float64
+	upperBound float64
+	integer    bool
+	line       int // last line defining the variable, for errors
+}
+
+type parsedRow struct {
+	name       string
+	lowerBound float64
+	upperBound float64
+	terms      map[*parsedVariable]float64
+	line       int // last line defining the row, for errors
+
+	// set for indicator constraints, enforced when the indicator equals indicatorValue
+	indicator      *parsedVariable
+	indicatorValue bool
+
+	// MPS only, the bounds are computed from them once the file is read
+	rowType  string
+	rhs      float64
+	rng      float64
+	hasRange bool
+}
+
+// parsedSOS is a special ordered set, its variables in the order of the file.
+type parsedSOS struct {
+	sosType SOSType
+	vars    []*parsedVariable
+	weights []float64
+	line    int // last line defining the set, for errors
+}
+
+func newModelBuilder() *modelBuilder {
+	return &modelBuilder{
+		varsByName: make(map[string]*parsedVariable),
+		rowsByName: make(map[string]*parsedRow),
+		objective:  make(map[*parsedVariable]float64),
+		sense:      Minimize,
+	}
+}
+
+// variable returns the variable with the given name, creating it at the given line with the default bounds [0, +inf)
+// if needed.
+func (b *modelBuilder) variable(line int, name string) *parsedVariable {
+	v, ok := b.varsByName[name]
+	if !ok {
+		v = &parsedVariable{name: name, index: len(b.vars), upperBound: math.Inf(1), line: line}
+		b.vars = append(b.vars, v)
+		b.varsByName[name] = v
+	}
+	return v
+}
+
+// addRow adds a row, the name must be unique if not empty.
+func (b *modelBuilder) addRow(line int, name string, lb, ub float64) (*parsedRow, error) {
+	if _, exists := b.rowsByName[name]; exists {
+		return nil, parseErrorf(line, "duplicate row %s", name)
+	}
+	row := &parsedRow{name: name, lowerBound: lb, upperBound: ub, terms: make(map[*parsedVariable]float64), line: line}
+	b.rows = append(b.rows, row)
+	if name != "" {
+		b.rowsByName[name] = row
+	}
+	return row, nil
+}
+
+// build builds the Model. An invalid variable, row or set, e.g. with a lower bound greater than its upper bound,
+// is reported as a ParseError at the last line defining it.
+func (b *modelBuilder) build() (*Model, error) {
+	m := NewModel()
+	invalid := func(line int) error {
+		if err := m.Err(); err != nil {
+			return &ParseError{Line: line, Msg: err.Error(), err: err}
+		}
+		return nil
+	}
+
+	vars := make([]*Variable, len(b.vars))
+	for i, v := range b.vars {
+		vars[i] = m.newVariable(v.name, v.lowerBound, v.upperBound, v.integer)
+		if err := invalid(v.line); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, row := range b.rows {
+		expr := NewLinearExpression()
+		for v, coeff := range row.terms {
+			expr.AddTerm(vars[v.index], coeff)
+		}
+		if row.indicator != nil {
+			m.addIndicatorConstraint(vars[row.indicator.index], row.indicatorValue, row.lowerBound, row.upperBound, expr)
+		} else {
+			m.addConstraint(row.name, row.lowerBound, row.upperBound, expr)
+		}
+		if err := invalid(row.line); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, sos := range b.sos {
+		sosVars := make([]*Variable, len(sos.vars))
+		for i, v := range sos.vars {
+			sosVars[i] = vars[v.index]
+		}
+		m.addSOS(sos.sosType, sosVars, sos.weights)
+		if err := invalid(sos.line); err != nil {
+			return nil, err
+		}
+	}
+
+	objective := NewLinearExpression()
+	for v, coeff := range b.objective {
+		objective.AddTerm(vars[v.index], coeff)
+	}
+	objective.AddConstant(b.offset)
+	m.SetObjective(objective, b.sense)
+	if err := invalid(b.objectiveLine); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// parseNumber parses a number, magnitudes from infinityThreshold on are infinite.
+func parseNumber(line int, s string) (float64, error) {
+	x, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, parseErrorf(line, "invalid number %q", s)
+	}
+	if math.Abs(x) >= infinityThreshold {
+		return math.Inf(int(math.Copysign(1, x))), nil
+	}
+	return x, nil
+}
+
+// ReadMPS reads a model in MPS format, free or fixed as long as names do not contain spaces.
+// Variables are integer between the 'INTORG' and 'INTEND' markers, and have the bounds [0, +inf) by default.
+// The first N row is the objective, other N rows are read as constraints without bounds.
+// Rows listed in the INDICATORS section are read as indicator constraints, and the sets of the SOS section
+// ("S1 SOS name" or "S2 SOS name" followed by "column weight" lines) as special ordered sets.
+func ReadMPS(r io.Reader) (*Model, error) {
+	p := &mpsParser{builder: newModelBuilder()}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if strings.TrimSpace(text) == "" || text[0] == '*' {
+			continue
+		}
+
+		var err error
+		if text[0] == ' ' || text[0] == '\t' {
+			err = p.parseDataLine(line, strings.Fields(text))
+		} else {
+			err = p.parseSectionLine(line, strings.Fields(text))
+		}
+		if err != nil {
+			return nil, err
+		}
+		if p.section == "ENDATA" {
+			return p.build()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, parseErrorf(line, "missing ENDATA")
+}
+
+type mpsParser struct {
+	builder        *modelBuilder
+	section        string
+	objectiveName  string
+	inIntegerBlock bool
+}
+
+func (p *mpsParser) parseSectionLine(line int, fields []string) error {
+	section := strings.ToUpper(fields[0])
+	switch section {
+	case "NAME", "ROWS", "COLUMNS", "RHS", "RANGES", "BOUNDS", "INDICATORS", "SOS", "ENDATA":
+	case "OBJSENSE":
+		if len(fields) > 1 { // free MPS allows the sense on the same line
+			return p.parseSense(line, fields[1])
+		}
+	default:
+		return parseErrorf(line, "unknown section %s", fields[0])
+	}
+	p.section = section
+	return nil
+}
+
+func (p *mpsParser) parseSense(line int, sense string) error {
+	switch strings.ToUpper(sense) {
+	case "MAX", "MAXIMIZE":
+		p.builder.sense = Maximize
+	case "MIN", "MINIMIZE":
+		p.builder.sense = Minimize
+	default:
+		return parseErrorf(line, "unknown objective sense %s", sense)
+	}
+	return nil
+}
+
+func (p *mpsParser) parseDataLine(line int, fields []string) error {
+	switch p.section {
+	case "OBJSENSE":
+		return p.parseSense(line, fields[0])
+	case "ROWS":
+		return p.parseRow(line, fields)
+	case "COLUMNS":
+		return p.parseColumn(line, fields)
+	case "RHS":
+		return p.parseRHS(line, fields)
+	case "RANGES":
+		return p.parseRange(line, fields)
+	case "BOUNDS":
+		return p.parseBound(line, fields)
+	case "SOS":
+		return p.parseSOS(line, fields)
+	case "INDICATORS":
+		return p.parseIndicator(line, fields)
+	default:
+		return parseErrorf(line, "unexpected data outside of a section")
+	}
+}
+
+func (p *mpsParser) parseRow(line int, fields []string) error {
+	if len(fields) != 2 {
+		return parseErrorf(line, "expected a row type and a row name")
+	}
+	rowType, name := strings.ToUpper(fields[0]), fields[1]
+
+	if rowType == "N" && p.objectiveName == "" {
+		p.objectiveName = name
+		return nil
+	}
+	if name == p.objectiveName {
+		return parseErrorf(line, "duplicate row %s", name)
+	}
+
+	switch rowType {
+	case "N", "L", "G", "E":
+		row, err := p.builder.addRow(line, name, math.Inf(-1), math.Inf(1))
+		if err != nil {
+			return err
+		}
+		row.rowType = rowType
+		return nil
+	default:
+		return parseErrorf(line, "unknown row type %s", fields[0])
+	}
+}
+
+// pairs returns the (row name, value) pairs of a COLUMNS, RHS or RANGES line, skipping the leading name if any.
+func (p *mpsParser) pairs(line int, fields []string) ([]string, error) {
+	if len(fields)%2 == 1 {
+		fields = fields[1:]
+	}
+	if len(fields) != 2 && len(fields) != 4 {
+		return nil, parseErrorf(line, "expected one or two (row, value) pairs")
+	}
+	return fields, nil
+}
+
+func (p *mpsParser) parseColumn(line int, fields []string) error {
+	if len(fields) >= 3 && strings.Trim(fields[1], "'") == "MARKER" {
+		switch strings.Trim(fields[len(fields)-1], "'") {
+		case "INTORG":
+			p.inIntegerBlock = true
+		case "INTEND":
+			p.inIntegerBlock = false
+		default:
+			return parseErrorf(line, "unknown marker %s", fields[len(fields)-1])
+		}
+		return nil
+	}
+
+	if len(fields) != 3 && len(fields) != 5 {
+		return parseErrorf(line, "expected a column name and one or two (row, value) pairs")
+	}
+	v := p.builder.variable(line, fields[0])
+	v.integer = p.inIntegerBlock
+
+	for i := 1; i < len(fields); i += 2 {
+		coeff, err := parseNumber(line, fields[i+1])
+		if err != nil {
+			return err
+		}
+		if fields[i] == p.objectiveName {
+			p.builder.objective[v] += coeff
+			p.builder.objectiveLine = line
+			continue
+		}
+		row, ok := p.builder.rowsByName[fields[i]]
+		if !ok {
+			return parseErrorf(line, "unknown row %s", fields[i])
+		}
+		row.terms[v] += coeff
+		row.line = line
+	}
+	return nil
+}
+
+func (p *mpsParser) parseRHS(line int, fields []string) error {
+	fields, err := p.pairs(line, fields)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(fields); i += 2 {
+		rhs, err := parseNumber(line, fields[i+1])
+		if err != nil {
+			return err
+		}
+		if fields[i] == p.objectiveName {
+			p.builder.offset = -rhs // the objective RHS is the negated offset
+			p.builder.objectiveLine = line
+			continue
+		}
+		row, ok := p.builder.rowsByName[fields[i]]
+		if !ok {
+			return parseErrorf(line, "unknown row %s", fields[i])
+		}
+		row.rhs, row.line = rhs, line
+	}
+	return nil
+}
+
+func (p *mpsParser) parseRange(line int, fields []string) error {
+	fields, err := p.pairs(line, fields)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(fields); i += 2 {
+		rng, err := parseNumber(line, fields[i+1])
+		if err != nil {
+			return err
+		}
+		row, ok := p.builder.rowsByName[fields[i]]
+		if !ok || row.rowType == "N" {
+			return parseErrorf(line, "no range can be set on row %s", fields[i])
+		}
+		row.rng, row.hasRange, row.line = rng, true, line
+	}
+	return nil
+}
+
+func (p *mpsParser) parseBound(line int, fields []string) error {
+	boundType := strings.ToUpper(fields[0])
+
+	var name, value string
+	switch boundType {
+	case "UP", "LO", "FX", "LI", "UI":
+		switch len(fields) {
+		case 4:
+			name, value = fields[2], fields[3]
+		case 3:
+			name, value = fields[1], fields[2]
+		default:
+			return parseErrorf(line, "expected a bound type, a column name and a value")
+		}
+	case "FR", "MI", "PL", "BV":
+		switch len(fields) {
+		case 3, 4:
+			name = fields[2]
+		case 2:
+			name = fields[1]
+		default:
+			return parseErrorf(line, "expected a bound type and a column name")
+		}
+	default:
+		return parseErrorf(line, "unsupported bound type %s", fields[0])
+	}
+
+	v, ok := p.builder.varsByName[name]
+	if !ok {
+		return parseErrorf(line, "unknown column %s", name)
+	}
+
+	var x float64
+	if value != "" {
+		var err error
+		if x, err = parseNumber(line, value); err != nil {
+			return err
+		}
+	}
+
+	switch boundType {
+	case "UP", "UI":
+		v.upperBound = x
+		if x < 0 && v.lowerBound == 0 {
+			v.lowerBound = math.Inf(-1) // as CPLEX does
+		}
+	case "LO", "LI":
+		v.lowerBound = x
+	case "FX":
+		v.lowerBound, v.upperBound = x, x
+	case "FR":
+		v.lowerBound, v.upperBound = math.Inf(-1), math.Inf(1)
+	case "MI":
+		v.lowerBound = math.Inf(-1)
+	case "PL":
+		v.upperBound = math.Inf(1)
+	case "BV":
+		v.lowerBound, v.upperBound = 0, 1
+	}
+	if boundType == "LI" || boundType == "UI" || boundType == "BV" {
+		v.integer = true
+	}
+	v.line = line
+	return nil
+}
+
+// parseIndicator parses an "IF row column value" line of the INDICATORS section: the row becomes an indicator
+// constraint, enforced when the binary column takes the value, 0 or 1.
+func (p *mpsParser) parseIndicator(line int, fields []string) error {
+	if len(fields) != 4 || strings.ToUpper(fields[0]) != "IF" {
+		return parseErrorf(line, "expected IF, a row name, a column name and a value")
+	}
+	row, ok := p.builder.rowsByName[fields[1]]
+	if !ok {
+		return parseErrorf(line, "unknown row %s", fields[1])
+	}
+	v, ok := p.builder.varsByName[fields[2]]
+	if !ok {
+		return parseErrorf(line, "unknown column %s", fields[2])
+	}
+	if fields[3] != "0" && fields[3] != "1" {
+		return parseErrorf(line, "the indicator value must be 0 or 1, got %s", fields[3])
+	}
+	row.indicator, row.indicatorValue, row.line = v, fields[3] == "1", line
+	return nil
+}
+
+// parseSOS parses the "S1 SOS name" (or S2) line starting a special ordered set, or a "column weight" entry of the set.
+func (p *mpsParser) parseSOS(line int, fields []string) error {
+	if sosType := strings.ToUpper(fields[0]); (sosType == "S1" || sosType == "S2") && len(fields) <= 3 &&
+		(len(fields) == 1 || strings.ToUpper(fields[1]) == "SOS") {
+		p.builder.sos = append(p.builder.sos, &parsedSOS{sosType: SOSType(sosType[1] - '0'), line: line})
+		return nil
+	}
+	if len(fields) != 2 {
+		return parseErrorf(line, "expected a column name and a weight")
+	}
+	if len(p.builder.sos) == 0 {
+		return parseErrorf(line, "expected S1 or S2 before the entries of a special ordered set")
+	}
+	v, ok := p.builder.varsByName[fields[0]]
+	if !ok {
+		return parseErrorf(line, "unknown column %s", fields[0])
+	}
+	weight, err := parseNumber(line, fields[1])
+	if err != nil {
+		return err
+	}
+	sos := p.builder.sos[len(p.builder.sos)-1]
+	sos.vars, sos.weights, sos.line = append(sos.vars, v), append(sos.weights, weight), line
+	return nil
+}
+
+// build computes the bounds of the rows from their type, right-hand side and range, then builds the Model.
+func (p *mpsParser) build() (*Model, error) {
+	for _, row := range p.builder.rows {
+		rhs, rng := row.rhs, math.Abs(row.rng)
+		switch row.rowType {
+		case "L":
+			row.lowerBound, row.upperBound = math.Inf(-1), rhs
+			if row.hasRange {
+				row.lowerBound = rhs - rng
+			}
+		case "G":
+			row.lowerBound, row.upperBound = rhs, math.Inf(1)
+			if row.hasRange {
+				row.upperBound = rhs + rng
+			}
+		case "E":
+			row.lowerBound, row.upperBound = rhs, rhs
+			if row.hasRange && row.rng > 0 {
+				row.upperBound = rhs + rng
+			} else if row.hasRange {
+				row.lowerBound = rhs - rng
+			}
+		}
+	}
+	return p.builder.build()
+}
+
+// ReadLP reads a model in CPLEX LP format: objective, constraints (including ranged constraints
+// written as lb <= expression <= ub, and indicator constraints written as b = 1 -> constraint),
+// bounds, general and binary variables, and special ordered sets written as "s1: S1:: x:1 y:2".
+// Variables have the bounds [0, +inf) by default. Quadratic terms and semi-continuous variables are not supported.
+func ReadLP(r io.Reader) (*Model, error) {
+	tokens, err := tokenizeLP(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &lpParser{tokens: tokens, builder: newModelBuilder()}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.builder.build()
+}
+
+type lpTokenKind int
+
+const (
+	lpName lpTokenKind = iota
+	lpNumber
+	lpSign     // + or -
+	lpOperator // <=, >=, =
+	lpColon
+	lpArrow // -> of indicator constraints
+	lpEOF
+)
+
+type lpToken struct {
+	kind lpTokenKind
+	text string
+	line int
+}
+
+// lpNameSymbols are the characters other than letters and digits allowed in LP names.
+const lpNameSymbols = `!"#$%&()/,.;?@_'{}|~`
+
+func isLPNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte(lpNameSymbols, c) >= 0
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func tokenizeLP(r io.Reader) ([]lpToken, error) {
+	var tokens []lpToken
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '\\'); i >= 0 {
+			text = text[:i] // comment
+		}
+
+		for i := 0; i < len(text); {
+			c := text[i]
+			start := i
+			switch {
+			case c == ' ' || c == '\t' || c == '\r':
+				i++
+				continue
+			case isDigit(c) || c == '.' && i+1 < len(text) && isDigit(text[i+1]):
+				for i < len(text) && (isDigit(text[i]) || text[i] == '.') {
+					i++
+				}
+				if i < len(text) && (text[i] == 'e' || text[i] == 'E') {
+					j := i + 1
+					if j < len(text) && (text[j] == '+' || text[j] == '-') {
+						j++
+					}
+					if j < len(text) && isDigit(text[j]) {
+						for i = j; i < len(text) && isDigit(text[i]); i++ {
+						}
+					}
+				}
+				tokens = append(tokens, lpToken{lpNumber, text[start:i], line})
+			case c == '<' || c == '>' || c == '=':
+				for i < len(text) && strings.IndexByte("<>=", text[i]) >= 0 {
+					i++
+				}
+				op, ok := map[string]string{"<": "<=", "<=": "<=", "=<": "<=", ">": ">=", ">=": ">=", "=>": ">=", "=": "="}[text[start:i]]
+				if !ok {
+					return nil, parseErrorf(line, "invalid operator %s", text[start:i])
+				}
+				tokens = append(tokens, lpToken{lpOperator, op, line})
+			case c == '-' && i+1 < len(text) && text[i+1] == '>':
+				i += 2
+				tokens = append(tokens, lpToken{lpArrow, "->", line})
+			case c == '+' || c == '-':
+				i++
+				tokens = append(tokens, lpToken{lpSign, text[start:i], line})
+			case c == ':':
+				i++
+				tokens = append(tokens, lpToken{lpColon, ":", line})
+			case isLPNameChar(c):
+				for i < len(text) && isLPNameChar(text[i]) {
+					i++
+				}
+				tokens = append(tokens, lpToken{lpName, text[start:i], line})
+			default:
+				return nil, parseErrorf(line, "unexpected character %q, quadratic terms are not supported", c)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return append(tokens, lpToken{lpEOF, "", max(line, 1)}), nil
+}
+
+type lpParser struct {
+	tokens  []lpToken
+	pos     int
+	builder *modelBuilder
+}
+
+func (p *lpParser) peek() lpToken { return p.at(0) }
+
+// at returns the token at the given offset from the current one, the EOF token past the end.
+func (p *lpParser) at(offset int) lpToken {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+offset]
+}
+
+func (p *lpParser) next() lpToken {
+	t := p.tokens[p.pos]
+	if t.kind != lpEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *lpParser) errorf(format string, args ...any) error {
+	return parseErrorf(p.peek().line, format, args...)
+}
+
+// section returns the section starting at the current token, if any, and the number of tokens of its keyword.
+// Keywords only start a section at the beginning of a line and when not followed by a colon,
+// elsewhere they are names.
+func (p *lpParser) section() (section string, length int) {
+	t := p.peek()
+	if t.kind == lpEOF {
+		return "end", 0
+	}
+	if t.kind != lpName || p.pos > 0 && p.tokens[p.pos-1].line == t.line || p.at(1).kind == lpColon {
+		return "", 0
+	}
+
+	nextIs := func(text string) bool {
+		next := p.at(1)
+		return next.kind == lpName && strings.EqualFold(next.text, text)
+	}
+	switch strings.ToLower(t.text) {
+	case "maximize", "maximise", "maximum", "max":
+		return "maximize", 1
+	case "minimize", "minimise", "minimum", "min":
+		return "minimize", 1
+	case "subject":
+		if nextIs("to") {
+			return "subject to", 2
+		}
+	case "such":
+		if nextIs("that") {
+			return "subject to", 2
+		}
+	case "st", "st.", "s.t.":
+		return "subject to", 1
+	case "bounds", "bound":
+		return "bounds", 1
+	case "general", "generals", "gen":
+		return "generals", 1
+	case "binary", "binaries", "bin":
+		return "binaries", 1
+	case "sos":
+		return "sos", 1
+	case "semi", "semis", "semi-continuous":
+		return "unsupported", 1
+	case "end":
+		return "end", 1
+	}
+	return "", 0
+}
+
+func (p *lpParser) parse() error {
+	section, length := p.section()
+	switch section {
+	case "maximize":
+		p.builder.sense = Maximize
+	case "minimize":
+		p.builder.sense = Minimize
+	default:
+		return p.errorf("expected the objective sense, Maximize or Minimize")
+	}
+	p.pos += length
+
+	if err := p.parseObjective(); err != nil {
+		return err
+	}
+
+	for {
+		section, length := p.section()
+		start := p.peek()
+		p.pos += length
+
+		var err error
+		switch section {
+		case "subject to":
+			err = p.parseConstraints()
+		case "bounds":
+			err = p.parseBounds()
+		case "generals", "binaries":
+			err = p.parseIntegers(section == "binaries")
+		case "sos":
+			err = p.parseSOS()
+		case "end":
+			return nil
+		case "unsupported":
+			return parseErrorf(start.line, "section %s is not supported", start.text)
+		default:
+			return parseErrorf(start.line, "unexpected %q", start.text)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseLabel consumes the "name:" label at the current token, if any.
+func (p *lpParser) parseLabel() string {
+	if p.peek().kind == lpName && p.at(1).kind == lpColon {
+		name := p.next().text
+		p.next()
+		return name
+	}
+	return ""
+}
+
+// parseExpression parses terms until an operator, a label or a section. It returns the terms and the sum of the constants.
+func (p *lpParser) parseExpression() (terms map[*parsedVariable]float64, constant float64, err error) {
+	terms = make(map[*parsedVariable]float64)
+	for {
+		if section, _ := p.section(); section != "" {
+			return terms, constant, nil
+		}
+		t := p.peek()
+		if t.kind == lpOperator || t.kind == lpName && p.at(1).kind == lpColon {
+			return terms, constant, nil
+		}
+
+		sign := 1.
+		for p.peek().kind == lpSign {
+			if p.next().text == "-" {
+				sign = -sign
+			}
+		}
+
+		coeff, hasCoeff := 1., false
+		if p.peek().kind == lpNumber {
+			if coeff, err = parseNumber(p.peek().line, p.next().text); err != nil {
+				return nil, 0, err
+			}
+			hasCoeff = true
+		}
+
+		section, _ := p.section() // a keyword after a number ends the expression, it is not a variable
+		if t := p.peek(); t.kind == lpName && p.at(1).kind != lpColon && section == "" {
+			p.next()
+			terms[p.builder.variable(t.line, t.text)] += sign * coeff
+		} else if hasCoeff {
+			constant += sign * coeff
+		} else {
+			return nil, 0, p.errorf("unexpected %q in expression", t.text)
+		}
+	}
+}
+
+func (p *lpParser) parseObjective() error {
+	p.builder.objectiveLine = p.peek().line
+	p.parseLabel()
+	terms, constant, err := p.parseExpression()
+	if err != nil {
+		return err
+	}
+	p.builder.objective = terms
+	p.builder.offset = constant
+	return nil
+}
+
+// parseSignedNumber parses a number with optional signs, or an infinity.
+func (p *lpParser) parseSignedNumber() (float64, error) {
+	sign := 1.
+	for p.peek().kind == lpSign {
+		if p.next().text == "-" {
+			sign = -sign
+		}
+	}
+
+	t := p.next()
+	switch {
+	case t.kind == lpNumber:
+		x, err := parseNumber(t.line, t.text)
+		return sign * x, err
+	case t.kind == lpName && (strings.EqualFold(t.text, "inf") || strings.EqualFold(t.text, "infinity")):
+		return math.Inf(int(sign)), nil
+	default:
+		return 0, parseErrorf(t.line, "expected a number, got %q", t.text)
+	}
+}
+
+func (p *lpParser) expectOperator() (string, error) {
+	t := p.next()
+	if t.kind != lpOperator {
+		return "", parseErrorf(t.line, "expected <=, >= or =, got %q", t.text)
+	}
+	return t.text, nil
+}
+
+// isNumberAhead reports whether the current tokens are a (signed) number or infinity.
+func (p *lpParser) isNumberAhead() bool {
+	i := p.pos
+	for p.tokens[i].kind == lpSign {
+		i++
+	}
+	t := p.tokens[i]
+	return t.kind == lpNumber || t.kind == lpName && (strings.EqualFold(t.text, "inf") || strings.EqualFold(t.text, "infinity"))
+}
+
+func (p *lpParser) parseConstraints() error {
+	for {
+		if section, _ := p.section(); section != "" {
+			return nil
+		}
+
+		line := p.peek().line
+		name := p.parseLabel()
+
+		// indicator constraint: b = 0 or 1 -> constraint
+		var indicator *parsedVariable
+		var indicatorValue bool
+		if p.peek().kind == lpName && p.at(1).text == "=" && p.at(2).kind == lpNumber && p.at(3).kind == lpArrow {
+			indicator = p.builder.variable(line, p.next().text)
+			p.next()
+			value := p.next().text
+			if value != "0" && value != "1" {
+				return parseErrorf(line, "the indicator value must be 0 or 1, got %s", value)
+			}
+			indicatorValue = value == "1"
+			p.next()
+		}
+
+		// ranged constraint: lb <= expression <= ub
+		var leftBound float64
+		var leftOp string
+		ranged := false
+		if p.isNumberAhead() {
+			start := p.pos
+			x, err := p.parseSignedNumber()
+			if err != nil {
+				return err
+			}
+			if p.peek().kind == lpOperator {
+				leftBound, leftOp, ranged = x, p.next().text, true
+			} else {
+				p.pos = start // a constant term of the expression
+			}
+		}
+
+		terms, constant, err := p.parseExpression()
+		if err != nil {
+			return err
+		}
+		op, err := p.expectOperator()
+		if err != nil {
+			return err
+		}
+		rhs, err := p.parseSignedNumber()
+		if err != nil {
+			return err
+		}
+		rhs -= constant
+
+		lb, ub := math.Inf(-1), math.Inf(1)
+		switch op {
+		case "<=":
+			ub = rhs
+		case ">=":
+			lb = rhs
+		case "=":
+			lb, ub = rhs, rhs
+		}
+		if ranged {
+			leftBound -= constant
+			switch {
+			case leftOp == "<=" && op == "<=":
+				lb = leftBound
+			case leftOp == ">=" && op == ">=":
+				ub = leftBound
+			default:
+				return parseErrorf(line, "a ranged constraint needs two inequalities in the same direction")
+			}
+		}
+
+		row, err := p.builder.addRow(line, name, lb, ub)
+		if err != nil {
+			return err
+		}
+		row.terms = terms
+		row.indicator, row.indicatorValue = indicator, indicatorValue
+	}
+}
+
+func (p *lpParser) parseBounds() error {
+	for {
+		if section, _ := p.section(); section != "" {
+			return nil
+		}
+
+		if p.isNumberAhead() { // x op name [op y]
+			x, err := p.parseSignedNumber()
+			if err != nil {
+				return err
+			}
+			op, err := p.expectOperator()
+			if err != nil {
+				return err
+			}
+			v, err := p.parseBoundedVariable()
+			if err != nil {
+				return err
+			}
+			setLPBound(v, reverseLPOperator(op), x)
+			if p.peek().kind == lpOperator {
+				if err := p.parseBoundRHS(v); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		v, err := p.parseBoundedVariable()
+		if err != nil {
+			return err
+		}
+		if t := p.peek(); t.kind == lpName && strings.EqualFold(t.text, "free") {
+			p.next()
+			v.lowerBound, v.upperBound = math.Inf(-1), math.Inf(1)
+			continue
+		}
+		if err := p.parseBoundRHS(v); err != nil {
+			return err
+		}
+	}
+}
+
+// parseBoundedVariable parses the variable name of a bound.
+func (p *lpParser) parseBoundedVariable() (*parsedVariable, error) {
+	t := p.next()
+	if t.kind != lpName {
+		return nil, parseErrorf(t.line, "expected a variable name, got %q", t.text)
+	}
+	v := p.builder.variable(t.line, t.text)
+	v.line = t.line
+	return v, nil
+}
+
+// parseBoundRHS parses "op y" after the variable v of a bound.
+func (p *lpParser) parseBoundRHS(v *parsedVariable) error {
+	op, err := p.expectOperator()
+	if err != nil {
+		return err
+	}
+	y, err := p.parseSignedNumber()
+	if err != nil {
+		return err
+	}
+	setLPBound(v, op, y)
+	return nil
+}
+
+// setLPBound applies "v op x".
+func setLPBound(v *parsedVariable, op string, x float64) {
+	switch op {
+	case "<=":
+		v.upperBound = x
+	case ">=":
+		v.lowerBound = x
+	case "=":
+		v.lowerBound, v.upperBound = x, x
+	}
+}
+
+// reverseLPOperator returns the operator op' such that "x op v" is "v op' x".
+func reverseLPOperator(op string) string {
+	switch op {
+	case "<=":
+		return ">="
+	case ">=":
+		return "<="
+	default:
+		return op
+	}
+}
+
+func (p *lpParser) parseIntegers(binary bool) error {
+	for {
+		if section, _ := p.section(); section != "" {
+			return nil
+		}
+		v, err := p.parseBoundedVariable()
+		if err != nil {
+			return err
+		}
+		v.integer = true
+		if binary {
+			v.lowerBound, v.upperBound = 0, 1
+		}
+	}
+}
+
+// parseSOS parses special ordered sets "name: S1:: x:1 y:2", the name being optional.
+func (p *lpParser) parseSOS() error {
+	isType := func(t lpToken) bool {
+		return t.kind == lpName && (strings.EqualFold(t.text, "S1") || strings.EqualFold(t.text, "S2"))
+	}
+	for {
+		if section, _ := p.section(); section != "" {
+			return nil
+		}
+
+		if !isType(p.peek()) || p.at(1).kind != lpColon || p.at(2).kind != lpColon {
+			p.parseLabel()
+		}
+		t := p.next()
+		if !isType(t) || p.next().kind != lpColon || p.next().kind != lpColon {
+			return parseErrorf(t.line, "expected S1:: or S2::, got %q", t.text)
+		}
+		sos := &parsedSOS{sosType: SOSType(t.text[1] - '0'), line: t.line}
+		p.builder.sos = append(p.builder.sos, sos)
+
+		// entries "x:1" until the next set or section
+		for p.peek().kind == lpName && p.at(1).kind == lpColon && (p.at(2).kind == lpNumber || p.at(2).kind == lpSign) {
+			v := p.builder.variable(p.peek().line, p.next().text)
+			p.next()
+			weight, err := p.parseSignedNumber()
+			if err != nil {
+				return err
+			}
+			sos.vars, sos.weights = append(sos.vars, v), append(sos.weights, weight)
+		}
+	}
+}
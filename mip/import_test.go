@@ -0,0 +1,199 @@
+package mip
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// rewrite writes the Model with write, reads it back with read and writes it again.
+func rewrite(t *testing.T, m *Model, write func(*Model, *strings.Builder) error, read func(string) (*Model, error)) (first, second string) {
+	t.Helper()
+	var sb strings.Builder
+	if err := write(m, &sb); err != nil {
+		t.Fatal(err)
+	}
+	read1, err := read(sb.String())
+	if err != nil {
+		t.Fatalf("%v in\n%s", err, sb.String())
+	}
+	var sb2 strings.Builder
+	if err := write(read1, &sb2); err != nil {
+		t.Fatal(err)
+	}
+	return sb.String(), sb2.String()
+}
+
+func readLP(s string) (*Model, error)  { return ReadLP(strings.NewReader(s)) }
+func readMPS(s string) (*Model, error) { return ReadMPS(strings.NewReader(s)) }
+
+func TestLPRoundTrip(t *testing.T) {
+	write := func(m *Model, sb *strings.Builder) error { return m.WriteLP(sb) }
+	// the ranged constraint is read back as two constraints named as they were written
+	if first, second := rewrite(t, exportTestModel(), write, readLP); first != second {
+		t.Errorf("got\n%s\nafter reading\n%s", second, first)
+	}
+
+	m := NewModel()
+	x := m.VarInt("x", -3, 7)
+	m.VarFloat("y", 0, math.Inf(1))
+	m.AddNamedConstraint("c", sumOf(m.Variables()...), GreaterThanOrEqual, 2)
+	objective := sumOf(x)
+	objective.AddConstant(-4)
+	m.SetObjective(objective, Minimize)
+	if first, second := rewrite(t, m, write, readLP); first != second {
+		t.Errorf("got\n%s\nafter reading\n%s", second, first)
+	}
+
+	// names reading like section keywords are replaced by generated ones
+	m = NewModel()
+	keywords := []string{"end", "max", "Minimize", "st", "s.t.", "subject", "such", "bounds", "bin", "gen", "sos", "semi"}
+	for _, name := range keywords {
+		m.VarInt(name, 0, 1)
+	}
+	m.AddNamedConstraint("min", sumOf(m.Variables()...), LessThanOrEqual, 3)
+	m.SetObjective(sumOf(m.Variables()...), Maximize)
+	first, second := rewrite(t, m, write, readLP)
+	if first != second || !strings.Contains(first, " R0: 1 C0 + 1 C1 + ") || !strings.Contains(first, " 1 C11 <= 3\n") {
+		t.Errorf("got\n%s\nafter reading\n%s", second, first)
+	}
+}
+
+func TestMPSRoundTrip(t *testing.T) {
+	for _, write := range []func(*Model, *strings.Builder) error{
+		func(m *Model, sb *strings.Builder) error { return m.WriteMPS(sb) },
+		func(m *Model, sb *strings.Builder) error { return m.WriteFixedMPS(sb) },
+	} {
+		if first, second := rewrite(t, exportTestModel(), write, readMPS); first != second {
+			t.Errorf("got\n%s\nafter reading\n%s", second, first)
+		}
+	}
+}
+
+func TestReadLP(t *testing.T) {
+	m, err := readLP(`\ a comment
+maximize
+ profit: 2 x + 3y - z + 4
+subject to
+ c1: x + y <= 4
+ -x + z >= -2
+ c3: 1 <= x - y <= 3
+ c4: 2 x - y = 2
+bounds
+ x <= 10
+ -inf <= y <= 5
+ z free
+general
+ x
+end`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, y, z := m.VariableByName("x"), m.VariableByName("y"), m.VariableByName("z")
+	if x.Lower() != 0 || x.Upper() != 10 || !x.IsInteger() || !math.IsInf(y.Lower(), -1) || y.Upper() != 5 || !math.IsInf(z.Lower(), -1) {
+		t.Errorf("unexpected bounds of %v, %v and %v", x, y, z)
+	}
+	if m.sense != Maximize || len(m.objective.terms) != 3 || m.objective.terms[y] != 3 || m.objective.terms[z] != -1 || m.objective.Constant() != 4 {
+		t.Errorf("got objective %v %v", m.sense, m.objective.terms)
+	}
+
+	tests := []struct {
+		name   string
+		lb, ub float64
+		terms  map[*Variable]float64
+	}{
+		{"c1", math.Inf(-1), 4, map[*Variable]float64{x: 1, y: 1}},
+		{"", -2, math.Inf(1), map[*Variable]float64{x: -1, z: 1}},
+		{"c3", 1, 3, map[*Variable]float64{x: 1, y: -1}},
+		{"c4", 2, 2, map[*Variable]float64{x: 2, y: -1}},
+	}
+	if m.NumConstraints() != len(tests) {
+		t.Fatalf("got %d constraints, want %d", m.NumConstraints(), len(tests))
+	}
+	for i, test := range tests {
+		c := m.Constraints()[i]
+		if c.Name() != test.name || c.lowerBound != test.lb || c.upperBound != test.ub || len(c.expr.terms) != len(test.terms) {
+			t.Errorf("got constraint %q [%v, %v] %v, want %+v", c.Name(), c.lowerBound, c.upperBound, c.expr.terms, test)
+			continue
+		}
+		for v, coeff := range test.terms {
+			if c.expr.terms[v] != coeff {
+				t.Errorf("%s: got the coefficient %v for %s, want %v", test.name, c.expr.terms[v], v.Name(), coeff)
+			}
+		}
+	}
+	if m.ConstraintByName("c3") != m.Constraints()[2] {
+		t.Error("c3 is not found by name")
+	}
+}
+
+func TestReadLPKeywordNames(t *testing.T) {
+	m, err := readLP("max\n obj: x + end + 2 bounds\nst\n min: end + st <= 3\nend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	objective, _ := m.Objective()
+	if objective.String() != "x + end + 2 bounds" || m.ConstraintByName("min").String() != "min: end + st <= 3" {
+		t.Errorf("got the objective %s and the constraints %v, want keywords within a line read as names", objective, m.Constraints())
+	}
+}
+
+func TestReadMPSDefaults(t *testing.T) {
+	m, err := readMPS(`NAME test
+ROWS
+ N  COST
+ L  LIM1
+ G  LIM2
+ N  FREE
+COLUMNS
+    X  COST  1  LIM1  1
+    X  LIM2  1
+    Y  COST  2  LIM2  1
+    Y  FREE  1
+RHS
+    RHS  LIM1  4  LIM2  1
+BOUNDS
+ UP BND  Y  -1
+ENDATA`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, y := m.VariableByName("X"), m.VariableByName("Y")
+	if x.Lower() != 0 || !math.IsInf(x.Upper(), 1) || !math.IsInf(y.Lower(), -1) || y.Upper() != -1 {
+		t.Errorf("got bounds [%v, %v] and [%v, %v]", x.Lower(), x.Upper(), y.Lower(), y.Upper())
+	}
+	free := m.ConstraintByName("FREE")
+	if m.NumConstraints() != 3 || !math.IsInf(free.lowerBound, -1) || !math.IsInf(free.upperBound, 1) {
+		t.Errorf("got %d constraints, the extra N row is [%v, %v]", m.NumConstraints(), free.lowerBound, free.upperBound)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		read     func(string) (*Model, error)
+		input    string
+		line     int
+		msg      string
+		semantic bool
+	}{
+		{readLP, "min\n x\nst\n c: x >= 1\n c: x <= 3\nend", 5, "duplicate row c", false},
+		{readLP, "min\n x^2\nend", 2, "quadratic", false},
+		{readLP, "max\n obj: x +\n end\nend", 3, `unexpected "end"`, false},
+		{readLP, "min\n x\nst\n c: x >= 1\nbounds\n 3 <= x <= 2\nend", 6, "lower bound 3 is greater than upper bound 2", true},
+		{readLP, "min\n x\nst\n c: x >= 1\nbounds\n x >= 5\n\n x <= 2\nend", 8, "lower bound 5 is greater than upper bound 2", true},
+		{readMPS, "ROWS\n N obj\nCOLUMNS\n    x obj 1\nBOUNDS\n LO BND x 5\n UP BND x 2\nENDATA", 7, "lower bound 5 is greater than upper bound 2", true},
+		{readMPS, "ROWS\n N obj\n L c\nCOLUMNS\n    x obj 1 c 1\n    y c nan\nENDATA", 6, "not finite", true},
+		{readMPS, "ROWS\n N obj\nCOLUMNS\n    x obj 1 c 1\nENDATA", 4, "unknown row c", false},
+		{readMPS, "ROWS\n N obj\nENDAT", 3, "unknown section", false},
+		{readMPS, "ROWS\n N obj\n", 2, "missing ENDATA", false},
+	}
+	for _, test := range tests {
+		_, err := test.read(test.input)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) || parseErr.Line != test.line || !strings.Contains(parseErr.Msg, test.msg) ||
+			errors.Is(err, ErrModelInvalid) != test.semantic {
+			t.Errorf("%q: got %v, want line %d: %s", test.input, err, test.line, test.msg)
+		}
+	}
+}
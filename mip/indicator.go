@@ -0,0 +1,186 @@
+package mip
+
+import (
+	"fmt"
+	"math"
+)
+
+// IndicatorConstraint is a linear constraint lb <= expression <= ub that is only enforced when a binary variable
+// takes a given value, e.g. "if the link is selected then its flow is at least the minimum".
+// Indicator constraints are kept apart from the constraints of the Model returned by Constraints.
+type IndicatorConstraint struct {
+	index      int
+	indicator  *Variable
+	active     bool
+	lowerBound float64
+	upperBound float64
+	expr       *LinearExpression
+
+	activity float64 // in the most recent solution
+}
+
+// AddIndicatorConstraint adds the constraint e t rhs, enforced only when the binary variable b is 1 if active is true,
+// 0 otherwise. The expression is copied and its constant moved to the right-hand side, like in AddConstraintExpr.
+//
+// Backends supporting indicator constraints (the OR-Tools SCIP, CP-SAT and Gurobi solvers) receive it as such.
+// For the others it is reformulated with big-M constraints, M being derived from the bounds of the variables of e:
+// these bounds must then be finite, and should be tight. The big-M constraints are rebuilt when these bounds are
+// widened between solves.
+// A b that is not a binary variable is recorded as the Model error.
+func (m *Model) AddIndicatorConstraint(b *Variable, active bool, e *LinearExpression, t ConstraintType, rhs float64) *IndicatorConstraint {
+	if b.model != m {
+		panic("variable " + b.name + " does not belong to this model")
+	}
+	lb, ub := constraintBounds(t, rhs)
+	return m.addIndicatorConstraint(b, active, lb, ub, e)
+}
+
+// addIndicatorConstraint adds the indicator constraint lb <= e <= ub, the constant of e is moved to the bounds.
+// Invalid bounds or coefficients and a non-binary indicator are recorded as the Model error.
+func (m *Model) addIndicatorConstraint(b *Variable, active bool, lb, ub float64, e *LinearExpression) *IndicatorConstraint {
+	m.checkNotSolving()
+	m.checkOwnership(e)
+
+	expr := e.Clone()
+	expr.constant = 0
+	ic := &IndicatorConstraint{
+		index:      len(m.indicators),
+		indicator:  b,
+		active:     active,
+		lowerBound: lb - e.constant,
+		upperBound: ub - e.constant,
+		expr:       expr,
+	}
+	m.indicators = append(m.indicators, ic)
+	m.checkCoefficients(ic.label(), e)
+	m.checkBounds(ic.label(), ic.lowerBound, ic.upperBound)
+	if !b.integer || b.lowerBound < 0 || b.upperBound > 1 {
+		m.setErr(fmt.Errorf("%w: %s: the indicator variable %s is not binary", ErrModelInvalid, ic.label(), b.label()))
+	}
+	return ic
+}
+
+// IndicatorConstraints returns the indicator constraints of the Model, in creation order.
+func (m *Model) IndicatorConstraints() []*IndicatorConstraint {
+	return append([]*IndicatorConstraint(nil), m.indicators...)
+}
+
+// label identifies the indicator constraint in messages.
+func (ic *IndicatorConstraint) label() string {
+	return fmt.Sprintf("indicator constraint #%d", ic.index)
+}
+
+// Index returns the position of the constraint among the indicator constraints of its Model.
+func (ic *IndicatorConstraint) Index() int { return ic.index }
+
+// Indicator returns the binary variable of the constraint, and the value for which the constraint is enforced.
+func (ic *IndicatorConstraint) Indicator() (*Variable, bool) { return ic.indicator, ic.active }
+
+// Lower returns the lower bound of the constraint, -Inf for a <= constraint.
+func (ic *IndicatorConstraint) Lower() float64 { return ic.lowerBound }
+
+// Upper returns the upper bound of the constraint, +Inf for a >= constraint.
+func (ic *IndicatorConstraint) Upper() float64 { return ic.upperBound }
+
+// Expression returns a copy of the linear expression of the constraint, its constant is always 0.
+func (ic *IndicatorConstraint) Expression() *LinearExpression { return ic.expr.Clone() }
+
+// Activity returns the value of the constraint's linear expression in the most recent solution.
+func (ic *IndicatorConstraint) Activity() float64 { return ic.activity }
+
+// String formats the constraint as "b = 1 -> expression <= ub".
+func (ic *IndicatorConstraint) String() string {
+	return fmt.Sprintf("%s = %d -> %s", ic.indicator.label(), ic.activeValue(), ic.row().relation())
+}
+
+// activeValue returns the value of the indicator variable enforcing the constraint, as an integer.
+func (ic *IndicatorConstraint) activeValue() int {
+	if ic.active {
+		return 1
+	}
+	return 0
+}
+
+// enforced reports whether the constraint is enforced for the given value of its indicator variable.
+func (ic *IndicatorConstraint) enforced(indicatorValue float64) bool {
+	return math.Abs(indicatorValue-float64(ic.activeValue())) <= feasibilityTolerance
+}
+
+// row returns the linear part of the indicator constraint as a Constraint outside of the Model, e.g. to export it.
+func (ic *IndicatorConstraint) row() *Constraint {
+	return &Constraint{lowerBound: ic.lowerBound, upperBound: ic.upperBound, expr: ic.expr}
+}
+
+// bigM returns the rows lb <= expression <= ub replacing the indicator constraint in Backends without native support:
+// expression + M*z <= ub + M and expression - M'*z >= lb - M', where z is the indicator variable, or 1 minus it
+// when the constraint is enforced at 0, and M and M' are how far the expression can go beyond the bounds.
+// Redundant rows are omitted. It returns an error if the expression is unbounded on the side of a bound.
+func (ic *IndicatorConstraint) bigM() ([]*Constraint, error) {
+	minActivity, maxActivity := ic.expr.constant, ic.expr.constant
+	for _, v := range ic.expr.Vars() {
+		coeff := ic.expr.terms[v]
+		if coeff == 0 {
+			continue
+		}
+		low, high := coeff*v.lowerBound, coeff*v.upperBound
+		if coeff < 0 {
+			low, high = high, low
+		}
+		minActivity += low
+		maxActivity += high
+	}
+
+	var rows []*Constraint
+	addRow := func(bound, m float64, upper bool) error {
+		if m <= 0 {
+			return nil // the expression never goes beyond the bound
+		}
+		if math.IsInf(m, 1) {
+			return fmt.Errorf("%w: %s: the variables of the expression need finite bounds for the big-M reformulation",
+				ErrModelInvalid, ic.label())
+		}
+		// upper: expression <= bound + M (1 - z), lower: expression >= bound - M (1 - z)
+		sign := 1.
+		if !upper {
+			sign = -1
+		}
+		expr := ic.expr.Clone()
+		lb, ub := math.Inf(-1), bound+sign*m
+		if ic.active {
+			expr.AddTerm(ic.indicator, sign*m)
+		} else {
+			expr.AddTerm(ic.indicator, -sign*m)
+			ub = bound
+		}
+		if !upper {
+			lb, ub = ub, math.Inf(1)
+		}
+		rows = append(rows, &Constraint{lowerBound: lb, upperBound: ub, expr: expr})
+		return nil
+	}
+
+	if !math.IsInf(ic.upperBound, 1) {
+		if err := addRow(ic.upperBound, maxActivity-ic.upperBound, true); err != nil {
+			return nil, err
+		}
+	}
+	if !math.IsInf(ic.lowerBound, -1) {
+		if err := addRow(ic.lowerBound, ic.lowerBound-minActivity, false); err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+// addBigMRows adds the big-M rows of the indicator constraint to the backend and returns them.
+func (s *Solver) addBigMRows(ic *IndicatorConstraint) ([]int, error) {
+	constraints, err := ic.bigM()
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]int, len(constraints))
+	for i, c := range constraints {
+		rows[i] = s.addRow(c)
+	}
+	return rows, nil
+}
@@ -0,0 +1,103 @@
+package mip
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSolveIndicatorWithBigM(t *testing.T) {
+	m := NewModel()
+	b := m.VarBool("b")
+	x := m.VarInt("x", 0, 10)
+	ic := m.AddIndicatorConstraint(b, true, sumOf(x), GreaterThanOrEqual, 7)
+	objective := NewLinearExpression()
+	objective.AddTerm(b, 10)
+	objective.AddTerm(x, -1)
+	m.SetObjective(objective, Maximize)
+
+	s, backend := newTestSolver(m)
+	result, err := s.Solve(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ObjectiveValue != 3 || b.Value() != 1 || x.Value() != 7 || ic.Activity() != 7 {
+		t.Errorf("got objective %v, b = %v, x = %v, want 3, 1 and 7", result.ObjectiveValue, b.Value(), x.Value())
+	}
+	if len(backend.rows) != 1 || m.NumConstraints() != 0 {
+		t.Errorf("got %d rows and %d constraints, want the big-M row only in the backend", len(backend.rows), m.NumConstraints())
+	}
+	if err := m.CheckSolution(map[*Variable]float64{b: 1, x: 6}); err == nil || !strings.Contains(err.Error(), "indicator constraint #0") {
+		t.Errorf("got %v, want the indicator constraint violated", err)
+	}
+	if err := m.CheckSolution(map[*Variable]float64{b: 0, x: 6}); err != nil {
+		t.Errorf("got %v for a constraint that is not enforced", err)
+	}
+}
+
+func TestSolveIndicatorAfterBoundChanges(t *testing.T) {
+	m := NewModel()
+	b := m.VarBool("b")
+	x := m.VarInt("x", 0, 10)
+	m.AddIndicatorConstraint(b, true, sumOf(x), LessThanOrEqual, 5)
+	objective := sumOf(x)
+	objective.AddTerm(b, 3)
+	m.SetObjective(objective, Maximize)
+
+	s, backend := newTestSolver(m)
+	if result, err := s.Solve(0); err != nil || result.ObjectiveValue != 10 {
+		t.Fatalf("got %+v, %v, want the optimum 10", result, err)
+	}
+
+	// the big-M row x + 5 b <= 10 would cut off x > 10
+	x.SetBounds(0, 20)
+	if result, err := s.Solve(0); err != nil || result.ObjectiveValue != 20 || x.Value() != 20 {
+		t.Errorf("got %+v, %v with x = %v, want the optimum 20 at x = 20", result, err, x.Value())
+	}
+	if len(backend.rows) != 2 || !math.IsInf(backend.rows[0].ub, 1) {
+		t.Errorf("got rows %+v, want the first big-M row relaxed and a new one", backend.rows)
+	}
+
+	x.SetBounds(2, 8)
+	if result, err := s.Solve(0); err != nil || result.ObjectiveValue != 8 || len(backend.rows) != 2 {
+		t.Errorf("got %+v, %v with %d rows, want the optimum 8 with the same rows", result, err, len(backend.rows))
+	}
+
+	x.SetBounds(0, math.Inf(1))
+	if _, err := s.Solve(0); !errors.Is(err, ErrModelInvalid) || math.IsInf(backend.rows[1].ub, 1) {
+		t.Errorf("got %v with rows %+v, want ErrModelInvalid keeping the big-M row", err, backend.rows)
+	}
+}
+
+func TestSolveIndicatorNatively(t *testing.T) {
+	m := NewModel()
+	b := m.VarBool("b")
+	x := m.VarFloat("x", 0, math.Inf(1))
+	m.AddIndicatorConstraint(b, false, sumOf(x), LessThanOrEqual, 5)
+
+	s, backend := newTestSolver(m)
+	backend.indicators = true
+	if _, err := s.Solve(0); err != nil {
+		t.Fatal(err)
+	}
+	if len(backend.indicatorRows) != 1 || len(backend.rows) != 0 || backend.indicatorRows[0].ub != 5 {
+		t.Errorf("got indicators %+v and rows %+v, want the indicator constraint only", backend.indicatorRows, backend.rows)
+	}
+}
+
+func TestIndicatorRoundTrip(t *testing.T) {
+	m := exportTestModel()
+	z, x := m.VariableByName("z"), m.VariableByName("x")
+	m.AddIndicatorConstraint(z, false, sumOf(x), GreaterThanOrEqual, 2)
+	m.AddIndicatorConstraint(z, true, sumOf(x), Equal, 4)
+
+	writeLP := func(m *Model, sb *strings.Builder) error { return m.WriteLP(sb) }
+	writeMPS := func(m *Model, sb *strings.Builder) error { return m.WriteMPS(sb) }
+	if first, second := rewrite(t, m, writeLP, readLP); first != second || !strings.Contains(first, "z = 0 -> 1 x >= 2") {
+		t.Errorf("got\n%s\nafter reading\n%s", second, first)
+	}
+	if first, second := rewrite(t, m, writeMPS, readMPS); first != second || !strings.Contains(first, "INDICATORS") {
+		t.Errorf("got\n%s\nafter reading\n%s", second, first)
+	}
+}
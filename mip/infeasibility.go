@@ -0,0 +1,164 @@
+package mip
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// IIS is an irreducible infeasible subsystem of a Model: its constraints and variable bounds
+// are infeasible together, and removing any one of them makes them feasible.
+type IIS struct {
+	Constraints []*Constraint
+	LowerBounds []*Variable // variables whose lower bound belongs to the subsystem
+	UpperBounds []*Variable // variables whose upper bound belongs to the subsystem
+}
+
+// String lists the constraints and the bounds of the subsystem, one per line.
+func (iis IIS) String() string {
+	var sb strings.Builder
+	for _, c := range iis.Constraints {
+		fmt.Fprintln(&sb, c)
+	}
+	for _, v := range iis.LowerBounds {
+		fmt.Fprintf(&sb, "%s >= %s\n", v.label(), formatNumber(v.lowerBound))
+	}
+	for _, v := range iis.UpperBounds {
+		fmt.Fprintf(&sb, "%s <= %s\n", v.label(), formatNumber(v.upperBound))
+	}
+	return sb.String()
+}
+
+// ExplainInfeasibility computes an IIS of an infeasible Model with a deletion filter: each constraint and each
+// finite variable bound is relaxed in turn, and left relaxed if the Model remains infeasible without it.
+// Integrality, indicator constraints and special ordered sets are never relaxed. Neither are the bounds of
+// the variables of indicator constraints and special ordered sets reformulated for the Backend (see
+// AddIndicatorConstraint and AddSOS1), as the reformulation is derived from them: their finite bounds are all
+// part of the subsystem, which may then not be irreducible.
+//
+// The Model is solved again through the Backend of the Solver, with a zero objective, once per constraint
+// and bound, which can take long on large models: the deadline of the context bounds each of these solves,
+// and cancelling it stops the computation. A constraint or bound whose removal gives a Model that is
+// neither proven feasible nor infeasible is kept, the subsystem is then infeasible but maybe not irreducible.
+//
+// The Model and the last solution of the Solver are left unchanged. It returns an error if the Model
+// is not proven infeasible.
+func (s *Solver) ExplainInfeasibility(ctx context.Context) (IIS, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.beginSolve()
+	defer s.endSolve()
+
+	if err := s.Err(); err != nil {
+		return IIS{}, err
+	}
+
+	objective, penalties := s.objective, s.penalties
+	s.objective, s.penalties = NewLinearExpression(), NewLinearExpression() // only feasibility matters
+	defer func() { s.objective, s.penalties = objective, penalties }()
+
+	status, err := s.solveStatus(ctx)
+	if err != nil {
+		return IIS{}, err
+	}
+	if status != Infeasible {
+		return IIS{}, fmt.Errorf("the model is not proven infeasible, the solver returned %s", status)
+	}
+
+	defer s.restoreBounds(s.saveBounds())
+	stillInfeasible := func() (bool, error) {
+		status, err := s.solveStatus(ctx)
+		return status == Infeasible, err
+	}
+	reformulated := make(map[*Variable]bool)
+	for _, r := range s.reformulations {
+		for _, v := range r.vars {
+			reformulated[v] = true
+		}
+	}
+
+	var iis IIS
+	for _, c := range s.constraints {
+		lb, ub := c.lowerBound, c.upperBound
+		c.lowerBound, c.upperBound = math.Inf(-1), math.Inf(1)
+		if infeasible, err := stillInfeasible(); err != nil {
+			return IIS{}, err
+		} else if !infeasible {
+			c.lowerBound, c.upperBound = lb, ub
+			iis.Constraints = append(iis.Constraints, c)
+		}
+	}
+	for _, v := range s.variables {
+		if reformulated[v] {
+			if !math.IsInf(v.lowerBound, -1) {
+				iis.LowerBounds = append(iis.LowerBounds, v)
+			}
+			if !math.IsInf(v.upperBound, 1) {
+				iis.UpperBounds = append(iis.UpperBounds, v)
+			}
+			continue
+		}
+		if lb := v.lowerBound; !math.IsInf(lb, -1) {
+			v.lowerBound = math.Inf(-1)
+			if infeasible, err := stillInfeasible(); err != nil {
+				return IIS{}, err
+			} else if !infeasible {
+				v.lowerBound = lb
+				iis.LowerBounds = append(iis.LowerBounds, v)
+			}
+		}
+		if ub := v.upperBound; !math.IsInf(ub, 1) {
+			v.upperBound = math.Inf(1)
+			if infeasible, err := stillInfeasible(); err != nil {
+				return IIS{}, err
+			} else if !infeasible {
+				v.upperBound = ub
+				iis.UpperBounds = append(iis.UpperBounds, v)
+			}
+		}
+	}
+	return iis, nil
+}
+
+// saveBounds returns the bounds of the constraints followed by the bounds of the variables.
+func (s *Solver) saveBounds() [][2]float64 {
+	bounds := make([][2]float64, 0, len(s.constraints)+len(s.variables))
+	for _, c := range s.constraints {
+		bounds = append(bounds, [2]float64{c.lowerBound, c.upperBound})
+	}
+	for _, v := range s.variables {
+		bounds = append(bounds, [2]float64{v.lowerBound, v.upperBound})
+	}
+	return bounds
+}
+
+// restoreBounds restores the bounds returned by saveBounds, they are passed to the backend at the next Solve.
+func (s *Solver) restoreBounds(bounds [][2]float64) {
+	for i, c := range s.constraints {
+		c.lowerBound, c.upperBound = bounds[i][0], bounds[i][1]
+	}
+	for i, v := range s.variables {
+		v.lowerBound, v.upperBound = bounds[len(s.constraints)+i][0], bounds[len(s.constraints)+i][1]
+	}
+}
+
+// solveStatus solves the Model as it is and returns the status, without reading the solution.
+func (s *Solver) solveStatus(ctx context.Context) (ResultStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return NotSolved, fmt.Errorf("%w: %w", ErrCancelled, err)
+	}
+	if err := s.materialise(); err != nil {
+		return NotSolved, err
+	}
+
+	s.backend.SetTimeLimit(contextTimeLimit(ctx))
+	status, interrupted := s.runBackend(ctx)
+	if err := s.backendErr(); err != nil {
+		return Abnormal, fmt.Errorf("%w: %w", ErrAbnormal, err)
+	}
+	if interrupted {
+		return Cancelled, fmt.Errorf("%w: %w", ErrCancelled, ctx.Err())
+	}
+	return status, nil
+}
@@ -0,0 +1,87 @@
+package mip
+
+import (
+	"context"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// solveIIS solves the subsystem alone: a copy of the Model keeping only the constraints and bounds of the IIS,
+// with integrality, indicator constraints and special ordered sets.
+func solveIIS(t *testing.T, m *Model, iis IIS) ResultStatus {
+	t.Helper()
+	cp := m.Copy()
+	for i, c := range cp.Constraints() {
+		if !slices.Contains(iis.Constraints, m.Constraints()[i]) {
+			c.lowerBound, c.upperBound = math.Inf(-1), math.Inf(1)
+		}
+	}
+	for i, v := range cp.Variables() {
+		if !slices.Contains(iis.LowerBounds, m.Variables()[i]) {
+			v.lowerBound = math.Inf(-1)
+		}
+		if !slices.Contains(iis.UpperBounds, m.Variables()[i]) {
+			v.upperBound = math.Inf(1)
+		}
+	}
+	s, _ := newTestSolver(cp)
+	result, _ := s.Solve(0)
+	return result.Status
+}
+
+func TestExplainInfeasibility(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 5)
+	y := m.VarInt("y", 0, 5)
+	demand, _ := m.AddNamedConstraint("demand", sumOf(x, y), GreaterThanOrEqual, 12)
+	m.AddNamedConstraint("cap", sumOf(x), LessThanOrEqual, 3)
+	m.SetObjective(sumOf(x), Maximize)
+
+	s, _ := newTestSolver(m)
+	iis, err := s.ExplainInfeasibility(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := iis.String(); got != "demand: x + y >= 12\nx <= 5\ny <= 5\n" || iis.Constraints[0] != demand {
+		t.Errorf("got the subsystem\n%s", got)
+	}
+	if status := solveIIS(t, m, iis); status != Infeasible {
+		t.Errorf("the subsystem alone is %v", status)
+	}
+	if objective, _ := m.Objective(); objective.String() != "x" || x.Lower() != 0 || m.ConstraintByName("cap").Upper() != 3 {
+		t.Error("the Model was changed")
+	}
+
+	y.SetBounds(0, 20)
+	if _, err := s.ExplainInfeasibility(context.Background()); err == nil || !strings.Contains(err.Error(), "not proven infeasible") {
+		t.Errorf("got %v for a feasible model", err)
+	}
+}
+
+func TestExplainInfeasibilityKeepsReformulatedBounds(t *testing.T) {
+	m := NewModel()
+	b := m.VarBool("b")
+	x := m.VarInt("x", -5, 10)
+	m.AddNamedConstraint("off", sumOf(b), LessThanOrEqual, 0)
+	m.AddIndicatorConstraint(b, true, sumOf(x), GreaterThanOrEqual, 12)
+	negative, _ := m.AddNamedConstraint("negative", sumOf(x), LessThanOrEqual, -7)
+
+	s, _ := newTestSolver(m)
+	iis, err := s.ExplainInfeasibility(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the big-M rows derive from the bounds of x and b, relaxing them would leave the rows stale
+	if len(iis.Constraints) != 1 || iis.Constraints[0] != negative || !slices.Contains(iis.LowerBounds, x) ||
+		!slices.Contains(iis.UpperBounds, b) {
+		t.Errorf("got the subsystem\n%s", iis)
+	}
+	if status := solveIIS(t, m, iis); status != Infeasible {
+		t.Errorf("the subsystem alone is %v", status)
+	}
+	if _, err := s.Solve(0); err == nil || x.Lower() != -5 || x.Upper() != 10 {
+		t.Errorf("got %v with x in [%v, %v], want the infeasible Model unchanged", err, x.Lower(), x.Upper())
+	}
+}
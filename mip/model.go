@@ -0,0 +1,264 @@
+package mip
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sync/atomic"
+)
+
+// Model holds the variables, constraints and objective of an optimization problem in Go memory.
+// A Model does not depend on any solver library: it can be built, inspected and copied freely,
+// and is only materialised into a solver when Solve is called.
+type Model struct {
+	variables   []*Variable
+	constraints []*Constraint
+	indicators  []*IndicatorConstraint
+	sos         []*SOSConstraint
+	objective   *LinearExpression
+	sense       OptimizationType
+	penalties   *LinearExpression // slack variable of a soft constraint -> penalty per unit, see AddSoftConstraint
+
+	// name indexes, a duplicate name is the Model error and the first variable or constraint of the name stays indexed
+	variablesByName   map[string]*Variable
+	constraintsByName map[string]*Constraint
+
+	err           error               // first error encountered while building the Model
+	invalidBounds map[*Variable]error // variables whose bounds are invalid, until they are corrected
+	solving       atomic.Bool         // set while a Solver solves the Model
+}
+
+// NewModel creates an empty Model. Like OR-Tools, the objective is minimized unless stated otherwise.
+func NewModel() *Model {
+	return &Model{
+		objective:         NewLinearExpression(),
+		penalties:         NewLinearExpression(),
+		sense:             Minimize,
+		variablesByName:   make(map[string]*Variable),
+		constraintsByName: make(map[string]*Constraint),
+		invalidBounds:     make(map[*Variable]error),
+	}
+}
+
+// Variables returns the variables of the Model, in creation order.
+func (m *Model) Variables() []*Variable {
+	return append([]*Variable(nil), m.variables...)
+}
+
+// Constraints returns the constraints of the Model, in creation order.
+func (m *Model) Constraints() []*Constraint {
+	return append([]*Constraint(nil), m.constraints...)
+}
+
+// VariableByName returns the variable with the given name, nil if there is none.
+// If the name was given to several variables, it returns the first one and Err returns ErrDuplicateName.
+func (m *Model) VariableByName(name string) *Variable {
+	return m.variablesByName[name]
+}
+
+// ConstraintByName returns the constraint with the given name, nil if there is none.
+func (m *Model) ConstraintByName(name string) *Constraint {
+	return m.constraintsByName[name]
+}
+
+// Err returns the first error encountered while building the Model, e.g. a duplicate variable name
+// or a variable whose lower bound is greater than its upper bound.
+// Other errors, e.g. a duplicate name, are permanent as what caused them cannot be changed, but invalid variable
+// bounds are only reported until they are corrected with SetBounds, after any other error, and for the variable
+// of lowest index first.
+// Solving a Model with an error fails with this error.
+func (m *Model) Err() error {
+	if m.err != nil {
+		return m.err
+	}
+	var first *Variable
+	for v := range m.invalidBounds {
+		if first == nil || v.index < first.index {
+			first = v
+		}
+	}
+	if first != nil {
+		return m.invalidBounds[first]
+	}
+	return nil
+}
+
+// setErr records err if it is the first error of the Model.
+func (m *Model) setErr(err error) {
+	if m.err == nil {
+		m.err = err
+	}
+}
+
+// checkBounds records an ErrModelInvalid error for NaN bounds or a lower bound greater than the upper bound.
+func (m *Model) checkBounds(what string, lb, ub float64) {
+	if err := boundsErr(what, lb, ub); err != nil {
+		m.setErr(err)
+	}
+}
+
+// checkVariableBounds records the error of invalid variable bounds, or clears it once they are valid.
+func (m *Model) checkVariableBounds(v *Variable) {
+	if err := boundsErr("variable "+v.label(), v.lowerBound, v.upperBound); err != nil {
+		m.invalidBounds[v] = err
+	} else {
+		delete(m.invalidBounds, v)
+	}
+}
+
+// boundsErr returns an ErrModelInvalid error for NaN bounds or a lower bound greater than the upper bound.
+func boundsErr(what string, lb, ub float64) error {
+	switch {
+	case math.IsNaN(lb) || math.IsNaN(ub):
+		return fmt.Errorf("%w: %s: bounds must not be NaN", ErrModelInvalid, what)
+	case lb > ub:
+		return fmt.Errorf("%w: %s: lower bound %v is greater than upper bound %v", ErrModelInvalid, what, lb, ub)
+	}
+	return nil
+}
+
+// checkCoefficients records an ErrModelInvalid error if the expression has a non-finite coefficient or constant.
+func (m *Model) checkCoefficients(what string, e *LinearExpression) {
+	if math.IsNaN(e.constant) || math.IsInf(e.constant, 0) {
+		m.setErr(fmt.Errorf("%w: %s: the constant %v is not finite", ErrModelInvalid, what, e.constant))
+	}
+	for _, v := range e.Vars() {
+		if coeff := e.terms[v]; math.IsNaN(coeff) || math.IsInf(coeff, 0) {
+			m.setErr(fmt.Errorf("%w: %s: the coefficient %v of variable %s is not finite", ErrModelInvalid, what, coeff, v.label()))
+		}
+	}
+}
+
+// beginSolve marks the Model as being solved, it panics if it already is.
+func (m *Model) beginSolve() {
+	if !m.solving.CompareAndSwap(false, true) {
+		panic("mip: Model solved by two Solvers concurrently")
+	}
+}
+
+func (m *Model) endSolve() { m.solving.Store(false) }
+
+// checkNotSolving panics if the Model is being solved, it is called by the methods modifying the Model.
+func (m *Model) checkNotSolving() {
+	if m.solving.Load() {
+		panic("mip: Model modified during Solve")
+	}
+}
+
+// NumVariables returns the number of variables in the Model.
+func (m *Model) NumVariables() int { return len(m.variables) }
+
+// NumConstraints returns the number of constraints in the Model.
+func (m *Model) NumConstraints() int { return len(m.constraints) }
+
+// Copy returns a deep copy of the Model.
+// Variables and constraints of the copy can be retrieved by index through Variables and Constraints,
+// i.e. m.Copy().Variables()[v.Index()] is the copy of v.
+func (m *Model) Copy() *Model {
+	cp := &Model{
+		variables:         make([]*Variable, len(m.variables)),
+		constraints:       make([]*Constraint, len(m.constraints)),
+		sense:             m.sense,
+		err:               m.err,
+		variablesByName:   make(map[string]*Variable, len(m.variablesByName)),
+		constraintsByName: make(map[string]*Constraint, len(m.constraintsByName)),
+		invalidBounds:     make(map[*Variable]error, len(m.invalidBounds)),
+	}
+
+	for i, v := range m.variables {
+		vCopy := *v
+		vCopy.model = cp
+		cp.variables[i] = &vCopy
+	}
+	for name, v := range m.variablesByName {
+		cp.variablesByName[name] = cp.variables[v.index]
+	}
+	for v, err := range m.invalidBounds {
+		cp.invalidBounds[cp.variables[v.index]] = err
+	}
+
+	for i, c := range m.constraints {
+		cCopy := *c
+		cCopy.expr = c.expr.remap(cp.variables)
+		cp.constraints[i] = &cCopy
+	}
+	for name, c := range m.constraintsByName {
+		cp.constraintsByName[name] = cp.constraints[c.index]
+	}
+
+	cp.indicators = make([]*IndicatorConstraint, len(m.indicators))
+	for i, ic := range m.indicators {
+		icCopy := *ic
+		icCopy.indicator = cp.variables[ic.indicator.index]
+		icCopy.expr = ic.expr.remap(cp.variables)
+		cp.indicators[i] = &icCopy
+	}
+
+	cp.sos = make([]*SOSConstraint, len(m.sos))
+	for i, sos := range m.sos {
+		sosCopy := *sos
+		sosCopy.vars = make([]*Variable, len(sos.vars))
+		for j, v := range sos.vars {
+			sosCopy.vars[j] = cp.variables[v.index]
+		}
+		sosCopy.weights = slices.Clone(sos.weights)
+		cp.sos[i] = &sosCopy
+	}
+
+	cp.objective = m.objective.remap(cp.variables)
+	cp.penalties = m.penalties.remap(cp.variables)
+	return cp
+}
+
+// feasibilityTolerance is the absolute tolerance used when checking solutions in Go,
+// in line with the default primal tolerance of the solvers.
+const feasibilityTolerance = 1e-6
+
+// CheckSolution returns an error describing the first variable bound, integrality requirement, constraint,
+// enforced indicator constraint or special ordered set violated by the given variable values,
+// or nil if they form a feasible solution of the Model.
+// Every variable of the Model must have a value.
+func (m *Model) CheckSolution(values map[*Variable]float64) error {
+	for _, v := range m.variables {
+		value, ok := values[v]
+		switch {
+		case !ok:
+			return fmt.Errorf("no value for variable %s", v.name)
+		case value < v.lowerBound-feasibilityTolerance || value > v.upperBound+feasibilityTolerance:
+			return fmt.Errorf("variable %s = %v is out of its bounds [%v, %v]", v.name, value, v.lowerBound, v.upperBound)
+		case v.integer && math.Abs(value-math.Round(value)) > feasibilityTolerance:
+			return fmt.Errorf("integer variable %s = %v is fractional", v.name, value)
+		}
+	}
+
+	for _, c := range m.constraints {
+		activity := c.expr.Eval(values)
+		if activity < c.lowerBound-feasibilityTolerance || activity > c.upperBound+feasibilityTolerance {
+			return fmt.Errorf("constraint %s is violated: %v is out of [%v, %v]", c.label(), activity, c.lowerBound, c.upperBound)
+		}
+	}
+	for _, ic := range m.indicators {
+		if !ic.enforced(values[ic.indicator]) {
+			continue
+		}
+		activity := ic.expr.Eval(values)
+		if activity < ic.lowerBound-feasibilityTolerance || activity > ic.upperBound+feasibilityTolerance {
+			return fmt.Errorf("%s is violated: %v is out of [%v, %v]", ic.label(), activity, ic.lowerBound, ic.upperBound)
+		}
+	}
+	for _, sos := range m.sos {
+		if violation := sos.violation(values); violation != "" {
+			return fmt.Errorf("%s is violated: %s", sos.label(), violation)
+		}
+	}
+	return nil
+}
+
+// checkOwnership panics if the expression refers to variables that were not created by this Model.
+func (m *Model) checkOwnership(e *LinearExpression) {
+	for _, v := range e.Vars() {
+		if v.model != m {
+			panic("variable " + v.name + " does not belong to this model")
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package mip
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestModelHoldsVariablesAndConstraints(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", -1, 4)
+	y := m.VarFloat("y", 0, math.Inf(1))
+	b := m.VarBool("b")
+	e := NewLinearExpression()
+	e.AddVar(x)
+	e.AddTerm(y, 2)
+	c := m.AddConstraintExpr(e, GreaterThanOrEqual, 3)
+	e.AddVar(b)
+
+	if m.NumVariables() != 3 || m.NumConstraints() != 1 {
+		t.Fatalf("got %d variables and %d constraints, want 3 and 1", m.NumVariables(), m.NumConstraints())
+	}
+	for i, v := range []*Variable{x, y, b} {
+		if m.Variables()[i] != v || v.Index() != i {
+			t.Errorf("variable %s is not at index %d", v.Name(), i)
+		}
+	}
+	if x.lowerBound != -1 || x.upperBound != 4 || !x.integer || y.integer || b.lowerBound != 0 || b.upperBound != 1 || !b.integer {
+		t.Error("unexpected variable bounds or integrality")
+	}
+	if m.Constraints()[0] != c || c.Index() != 0 || c.lowerBound != 3 || !math.IsInf(c.upperBound, 1) {
+		t.Errorf("unexpected constraint bounds [%v, %v]", c.lowerBound, c.upperBound)
+	}
+	if len(c.expr.terms) != 2 || c.expr.terms[y] != 2 {
+		t.Errorf("got the terms %v, want x + 2 y copied before b was added", c.expr.terms)
+	}
+}
+
+func TestModelRecordsInvalidBounds(t *testing.T) {
+	m := NewModel()
+	x := m.VarFloat("x", 0, 1)
+	y := m.VarFloat("y", 3, 2)
+	x.SetBounds(math.NaN(), 1)
+	if err := m.Err(); !errors.Is(err, ErrModelInvalid) || !strings.Contains(err.Error(), "variable x") {
+		t.Errorf("got %v, want the error of x", err)
+	}
+	if err := m.Copy().Err(); err == nil || err.Error() != m.Err().Error() {
+		t.Errorf("got %v for the copy, want %v", err, m.Err())
+	}
+
+	x.SetBounds(0, 1)
+	if err := m.Err(); !errors.Is(err, ErrModelInvalid) || !strings.Contains(err.Error(), "variable y") {
+		t.Errorf("got %v, want the error of y", err)
+	}
+	y.SetBounds(2, 3)
+	if err := m.Err(); err != nil {
+		t.Errorf("got %v after correcting the bounds", err)
+	}
+}
+
+func TestModelRecordsInvalidCoefficients(t *testing.T) {
+	m := NewModel()
+	x := m.VarFloat("x", 0, 1)
+	e := NewLinearExpression()
+	e.AddTerm(x, math.Inf(1))
+	m.SetObjective(e, Maximize)
+	m.AddConstraintExpr(sumOf(x), Equal, math.NaN())
+	if err := m.Err(); !errors.Is(err, ErrModelInvalid) || !strings.Contains(err.Error(), "objective: the coefficient +Inf of variable x") {
+		t.Errorf("got %v, want the error of the objective", err)
+	}
+}
+
+func TestModelPanicsOnForeignVariable(t *testing.T) {
+	other := NewModel()
+	e := NewLinearExpression()
+	e.AddVar(other.VarBool("x"))
+
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic for a variable of another model")
+		}
+	}()
+	NewModel().AddConstraintExpr(e, LessThanOrEqual, 1)
+}
+
+func TestModelCopy(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 10)
+	y := m.VarFloat("y", -1, 1)
+	e := NewLinearExpression()
+	e.AddTerm(x, 2)
+	e.AddVar(y)
+	m.AddConstraintExpr(e, LessThanOrEqual, 5)
+	m.SetObjective(e, Maximize)
+
+	cp := m.Copy()
+	cx, cy := cp.Variables()[0], cp.Variables()[1]
+	if cx == x || cx.Name() != "x" || cy.lowerBound != -1 || cx.model != cp {
+		t.Fatal("the variables are not copied")
+	}
+	cc := cp.Constraints()[0]
+	if cc == m.Constraints()[0] || cc.expr.terms[cx] != 2 || cc.expr.terms[x] != 0 || cc.upperBound != 5 {
+		t.Errorf("the constraint is not copied onto the copied variables: %v", cc.expr.terms)
+	}
+	if cp.objective.terms[cy] != 1 || cp.objective.terms[y] != 0 || cp.sense != Maximize {
+		t.Errorf("the objective is not copied: %v", cp.objective.terms)
+	}
+
+	cx.upperBound = 3
+	cp.VarBool("z")
+	if x.upperBound != 10 || m.NumVariables() != 2 {
+		t.Error("changing the copy changed the original")
+	}
+}
+
+func TestCheckSolution(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 5)
+	y := m.VarFloat("y", 0, 5)
+	e := NewLinearExpression()
+	e.AddVar(x)
+	e.AddVar(y)
+	m.AddConstraintExpr(e, LessThanOrEqual, 6)
+
+	tests := []struct {
+		x, y float64
+		want string
+	}{
+		{2, 3.5, ""},
+		{6, 0, "out of its bounds"},
+		{1.5, 0, "fractional"},
+		{4, 3, "constraint #0 is violated"},
+	}
+	for _, test := range tests {
+		err := m.CheckSolution(map[*Variable]float64{x: test.x, y: test.y})
+		if test.want == "" && err != nil || test.want != "" && (err == nil || !strings.Contains(err.Error(), test.want)) {
+			t.Errorf("x = %v, y = %v: got %v, want %q", test.x, test.y, err, test.want)
+		}
+	}
+	if err := m.CheckSolution(map[*Variable]float64{x: 1}); err == nil {
+		t.Error("no error for a missing value")
+	}
+}
+
+func TestModelNames(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 3)
+	m.VarBool("")
+	m.VarBool("")
+	if m.VariableByName("x") != x || m.VariableByName("") != nil || m.VariableByName("y") != nil || m.Err() != nil {
+		t.Errorf("got %v and error %v, want x and no error for the unnamed variables", m.VariableByName("x"), m.Err())
+	}
+
+	c, err := m.AddNamedConstraint("c", sumOf(x), LessThanOrEqual, 4)
+	if err != nil || m.ConstraintByName("c") != c || c.Name() != "c" {
+		t.Fatalf("got %v, %v, want the constraint c", c, err)
+	}
+	if _, err := m.AddNamedConstraint("c", sumOf(x), LessThanOrEqual, 1); !errors.Is(err, ErrDuplicateName) || m.NumConstraints() != 1 {
+		t.Errorf("got %v and %d constraints, want ErrDuplicateName and 1", err, m.NumConstraints())
+	}
+	m.addConstraint("c", 0, 1, sumOf(x))
+	if !errors.Is(m.Err(), ErrDuplicateName) || m.ConstraintByName("c") != c {
+		t.Errorf("got %v, want ErrDuplicateName and the first c", m.Err())
+	}
+}
+
+func TestSolveWithDuplicateVariableNames(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 3)
+	m.VarInt("x", 2, 1)
+	if !errors.Is(m.Err(), ErrDuplicateName) || m.VariableByName("x") != x {
+		t.Errorf("got %v, want ErrDuplicateName and the first x", m.Err())
+	}
+	// the duplicate name comes first and stays once the bounds are corrected
+	m.Variables()[1].SetBounds(0, 2)
+	if err := m.Copy().Err(); !errors.Is(err, ErrDuplicateName) || m.Copy().VariableByName("x").Index() != 0 {
+		t.Errorf("got %v for the copy, want ErrDuplicateName and the first x", err)
+	}
+
+	s, b := newTestSolver(m)
+	if _, err := s.Solve(0); !errors.Is(err, ErrDuplicateName) || b.solves != 0 {
+		t.Errorf("got %v after %d solves, want ErrDuplicateName without solving", err, b.solves)
+	}
+}
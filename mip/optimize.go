@@ -1,7 +1,6 @@
 package mip
 
-import "C"
-import "math"
+import "fmt"
 
 type OptimizationType int
 
@@ -10,41 +9,21 @@ const (
 	Minimize
 )
 
-// SetObjective sets the objective function of the Solver to the given linear expression and optimization type.
-func (s *Solver) SetObjective(le *LinearExpression, tp OptimizationType) {
-	svr := s
+// SetObjective sets the objective function of the Model to the given linear expression and optimization type,
+// replacing any previous objective. The constant of the expression is the objective offset.
+// The expression is copied, later changes to it do not affect the objective.
+func (m *Model) SetObjective(le *LinearExpression, tp OptimizationType) {
+	m.checkNotSolving()
+	m.checkOwnership(le)
 
-	for v, coefficient := range le.terms {
-		svr.setObjectiveCoefficient(v, coefficient)
-	}
-
-	switch tp {
-	case Maximize:
-		svr.setMaximization()
-	case Minimize:
-		svr.setMinimization()
-	}
-}
-
-// ObjectiveValue returns the current best objective value found by the solver.
-func (s *Solver) ObjectiveValue() float64 {
-	return s.objectiveValue()
-}
-
-// BestBound returns what's currently the best bound.
-// for example, if the problem is being minimized, and the best bound is 100,
-// then the theoretical optimal objective is at least 100.
-// This can be used to evaluate the quality of the solution.
-func (s *Solver) BestBound() float64 {
-	return s.getBestBound()
+	m.objective = le.Clone()
+	m.sense = tp
+	m.checkCoefficients("objective", le)
 }
 
-// Gap returns the relative gap between the best integer solution found and the best bound.
-// A gap of 0.5 means that the best solution is at most 50% away from the best bound.
-func (s *Solver) Gap() float64 {
-	objVal := s.ObjectiveValue()
-	bestBound := s.BestBound()
-	return math.Abs((bestBound - objVal) / objVal)
+// Objective returns a copy of the objective function of the Model, including its offset, and its optimization type.
+func (m *Model) Objective() (*LinearExpression, OptimizationType) {
+	return m.objective.Clone(), m.sense
 }
 
 // ResultStatus represents the status of the optimization result.
@@ -58,4 +37,39 @@ const (
 	Abnormal
 	ModelInvalid
 	NotSolved
+	Cancelled // the solve was stopped by its context, not returned by backends
 )
+
+// BasisStatus is the status of a variable or a constraint in the simplex basis of a linear program.
+type BasisStatus int
+
+const (
+	BasisFree BasisStatus = iota
+	AtLowerBound
+	AtUpperBound
+	FixedValue
+	Basic
+)
+
+func (s ResultStatus) String() string {
+	switch s {
+	case Optimal:
+		return "optimal"
+	case Feasible:
+		return "feasible"
+	case Infeasible:
+		return "infeasible"
+	case Unbounded:
+		return "unbounded"
+	case Abnormal:
+		return "abnormal"
+	case ModelInvalid:
+		return "model invalid"
+	case NotSolved:
+		return "not solved"
+	case Cancelled:
+		return "cancelled"
+	default:
+		return fmt.Sprintf("ResultStatus(%d)", int(s))
+	}
+}
@@ -0,0 +1,89 @@
+package mip
+
+import "fmt"
+
+// SolverParams are the parameters of a solve. The zero value of each field keeps the solver default.
+type SolverParams struct {
+	// RelativeGap stops the solve once the relative gap between the best solution and the best bound
+	// is below it, e.g. 0.01 to stop at 1%.
+	RelativeGap float64
+	// AbsoluteGap stops the solve once the absolute gap between the best solution and the best bound is below it.
+	// Only SCIP, CP-SAT and Gurobi support it, solving with another solver fails.
+	AbsoluteGap float64
+	// NumThreads is the number of threads the solver may use.
+	NumThreads int
+	// RandomSeed, if not nil, sets the random seed of the solver, 0 included.
+	// Only SCIP, CP-SAT, Gurobi and GLOP support it, solving with another solver fails.
+	RandomSeed *int
+	// Presolve turns the presolve on or off.
+	Presolve PresolveMode
+	// LPAlgorithm is the algorithm used to solve the linear programs (relaxations).
+	LPAlgorithm LPAlgorithm
+	// Verbose enables the solver logs on the standard output.
+	Verbose bool
+	// SolverSpecific is passed as is to the underlying solver, in its own parameter format.
+	SolverSpecific string
+}
+
+type PresolveMode int
+
+const (
+	PresolveDefault PresolveMode = iota
+	PresolveOn
+	PresolveOff
+)
+
+type LPAlgorithm int
+
+const (
+	LPAlgorithmDefault LPAlgorithm = iota
+	DualSimplex
+	PrimalSimplex
+	Barrier
+)
+
+// specificFormats are the formats of the parameters without generic OR-Tools parameter,
+// in the solver specific parameter format of the solvers supporting them.
+var specificFormats = map[string]struct{ absoluteGap, randomSeed string }{
+	SCIP:   {"limits/absgap = %v", "randomization/randomseedshift = %d"},
+	CPSAT:  {"absolute_gap_limit:%v", "random_seed:%d"},
+	GUROBI: {"MIPGapAbs %v", "Seed %d"},
+	GLOP:   {"", "random_seed:%d"},
+}
+
+// specificParams returns the absolute gap and the random seed in the solver specific parameter format
+// of the solver type, followed by SolverSpecific. It returns an error if the solver does not support one of them.
+func (p SolverParams) specificParams(solverType string) ([]string, error) {
+	formats := specificFormats[solverType]
+	var specific []string
+	if p.AbsoluteGap > 0 {
+		if formats.absoluteGap == "" {
+			return nil, fmt.Errorf("%s does not support the absolute gap parameter", solverType)
+		}
+		specific = append(specific, fmt.Sprintf(formats.absoluteGap, p.AbsoluteGap))
+	}
+	if p.RandomSeed != nil {
+		if formats.randomSeed == "" {
+			return nil, fmt.Errorf("%s does not support the random seed parameter", solverType)
+		}
+		specific = append(specific, fmt.Sprintf(formats.randomSeed, *p.RandomSeed))
+	}
+	if p.SolverSpecific != "" {
+		specific = append(specific, p.SolverSpecific)
+	}
+	return specific, nil
+}
+
+// SetParams sets the parameters used by the next solves.
+func (s *Solver) SetParams(p SolverParams) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.params = p
+}
+
+// Params returns the parameters used by the next solves.
+func (s *Solver) Params() SolverParams {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.params
+}
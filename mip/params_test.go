@@ -0,0 +1,68 @@
+package mip
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSolverParams(t *testing.T) {
+	m := NewModel()
+	m.VarBool("x")
+	s, b := newTestSolver(m)
+
+	params := SolverParams{RelativeGap: 0.01, NumThreads: 4, Presolve: PresolveOff, LPAlgorithm: Barrier}
+	s.SetParams(params)
+	if _, err := s.Solve(0); err != nil || b.params != params || s.Params() != params {
+		t.Errorf("got %v with parameters %+v, want %+v", err, b.params, params)
+	}
+
+	b.paramsErr = errors.New("random seed not supported")
+	seed := 1
+	s.SetParams(SolverParams{RandomSeed: &seed})
+	if _, err := s.Solve(0); !errors.Is(err, b.paramsErr) || b.solves != 1 {
+		t.Errorf("got %v after %d solves, want the parameter error without solving", err, b.solves)
+	}
+}
+
+func TestSolverParamsWithoutParamSetter(t *testing.T) {
+	m := NewModel()
+	m.VarBool("x")
+	s := NewSolverWithBackend(m, struct{ Backend }{&testBackend{}})
+	s.SetParams(SolverParams{Verbose: true})
+	if _, err := s.Solve(0); err == nil {
+		t.Error("no error for parameters given to a backend without parameters")
+	}
+	s.SetParams(SolverParams{})
+	if _, err := s.Solve(0); err != nil {
+		t.Errorf("got %v with the default parameters", err)
+	}
+}
+
+func TestSpecificParams(t *testing.T) {
+	zero := 0
+	tests := []struct {
+		solverType string
+		params     SolverParams
+		want       string
+	}{
+		{SCIP, SolverParams{AbsoluteGap: 0.5, RandomSeed: &zero, SolverSpecific: "limits/nodes = 10"},
+			"limits/absgap = 0.5|randomization/randomseedshift = 0|limits/nodes = 10"},
+		{CPSAT, SolverParams{AbsoluteGap: 2, RandomSeed: &zero}, "absolute_gap_limit:2|random_seed:0"},
+		{GUROBI, SolverParams{AbsoluteGap: 1e-3}, "MIPGapAbs 0.001"},
+		{GLOP, SolverParams{RandomSeed: &zero}, "random_seed:0"},
+		{CBC, SolverParams{RelativeGap: 0.1}, ""},
+		{CBC, SolverParams{RandomSeed: &zero}, "error: CBC does not support the random seed parameter"},
+		{GLOP, SolverParams{AbsoluteGap: 1}, "error: GLOP does not support the absolute gap parameter"},
+	}
+	for _, test := range tests {
+		specific, err := test.params.specificParams(test.solverType)
+		got := strings.Join(specific, "|")
+		if err != nil {
+			got = "error: " + err.Error()
+		}
+		if got != test.want {
+			t.Errorf("%s %+v: got %q, want %q", test.solverType, test.params, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package mip
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// Pool solves independent Models in parallel, running at most a fixed number of solves at once.
+// Each solve gets its own Solver, which is released when the solve ends: the solution is read from
+// the variables and constraints of the Model, like after Solver.Solve.
+// A Pool can be used from several goroutines. A Model the Pool is already solving is not solved again,
+// and a Model must not be solved by a Pool and a Solver at the same time.
+type Pool struct {
+	solverType string
+	params     SolverParams
+	slots      chan struct{}
+
+	mu     sync.Mutex
+	models map[*Model]bool // models waiting for a slot or being solved
+}
+
+// NewPool creates a Pool solving with the given solver type, running at most maxConcurrent solves at once.
+// A non-positive maxConcurrent means runtime.GOMAXPROCS(0).
+// It returns an error if the solver type is not available.
+func NewPool(solverType string, maxConcurrent int, params SolverParams) (*Pool, error) {
+	if err := checkSolverType(solverType); err != nil {
+		return nil, err
+	}
+	if maxConcurrent <= 0 {
+		maxConcurrent = runtime.GOMAXPROCS(0)
+	}
+	return &Pool{
+		solverType: solverType,
+		params:     params,
+		slots:      make(chan struct{}, maxConcurrent),
+		models:     make(map[*Model]bool),
+	}, nil
+}
+
+// Solve solves the Model once a slot is free, with the deadline of the context as time limit.
+// If the context is done before the solve starts, the status is Cancelled and the error wraps ErrCancelled
+// and ctx.Err(), see Solver.SolveContext otherwise. If the Pool is already solving the Model, e.g. as it is
+// passed twice to SolveAll, the status is NotSolved and the error wraps ErrNotSolved.
+func (p *Pool) Solve(ctx context.Context, m *Model) (SolveResult, error) {
+	if !p.claim(m) {
+		return SolveResult{Status: NotSolved}, fmt.Errorf("%w: the pool is already solving the model", ErrNotSolved)
+	}
+	defer p.release(m)
+
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return SolveResult{Status: Cancelled}, fmt.Errorf("%w: %w", ErrCancelled, ctx.Err())
+	}
+	defer func() { <-p.slots }()
+
+	s, err := NewSolverForModel(m, p.solverType)
+	if err != nil {
+		return SolveResult{Status: NotSolved}, err
+	}
+	defer s.ReleaseResources()
+
+	s.SetParams(p.params)
+	return s.SolveContext(ctx)
+}
+
+// claim records that the Pool solves the Model, it reports false if it already does.
+func (p *Pool) claim(m *Model) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.models[m] {
+		return false
+	}
+	p.models[m] = true
+	return true
+}
+
+func (p *Pool) release(m *Model) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.models, m)
+}
+
+// SolveAll solves the Models in parallel and returns their results and errors, in the order of the Models.
+// A Model given several times is solved once, the other times fail with ErrNotSolved, see Solve.
+func (p *Pool) SolveAll(ctx context.Context, models []*Model) ([]SolveResult, []error) {
+	results := make([]SolveResult, len(models))
+	errs := make([]error, len(models))
+
+	var wg sync.WaitGroup
+	for i, m := range models {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = p.Solve(ctx, m)
+		}()
+	}
+	wg.Wait()
+	return results, errs
+}
@@ -0,0 +1,85 @@
+package mip
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoolSolveAll(t *testing.T) {
+	var running, highest atomic.Int32
+	countSolves := func(status ResultStatus) ResultStatus {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for h := highest.Load(); n > h && !highest.CompareAndSwap(h, n); {
+			h = highest.Load()
+		}
+		time.Sleep(10 * time.Millisecond)
+		return status
+	}
+	var mu sync.Mutex
+	var backends []*testBackend
+	defer func(available func(string) bool, newBackend func(string) (Backend, error)) {
+		isSolverTypeAvailable, newDefaultBackend = available, newBackend
+	}(isSolverTypeAvailable, newDefaultBackend)
+	isSolverTypeAvailable = func(string) bool { return true }
+	newDefaultBackend = func(string) (Backend, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		b := &testBackend{onSolve: countSolves}
+		backends = append(backends, b)
+		return b, nil
+	}
+
+	p, err := NewPool(CBC, 2, SolverParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	models := make([]*Model, 6)
+	for i := range models {
+		models[i] = NewModel()
+		x := models[i].VarInt("x", 0, i)
+		models[i].SetObjective(sumOf(x), Maximize)
+	}
+	results, errs := p.SolveAll(context.Background(), models)
+	for i, result := range results {
+		if errs[i] != nil || result.ObjectiveValue != float64(i) || models[i].Variables()[0].Value() != float64(i) {
+			t.Errorf("model %d: got %+v, %v, want the objective %d", i, result, errs[i], i)
+		}
+	}
+	if highest.Load() > 2 || len(backends) != 6 {
+		t.Errorf("got %d solves at once with %d backends, want at most 2 and 6", highest.Load(), len(backends))
+	}
+	for _, b := range backends {
+		if !b.released {
+			t.Error("a backend was not released")
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p.slots <- struct{}{}
+	p.slots <- struct{}{}
+	if result, err := p.Solve(ctx, models[0]); !errors.Is(err, ErrCancelled) || result.Status != Cancelled {
+		t.Errorf("got %+v, %v, want ErrCancelled before solving", result, err)
+	}
+	<-p.slots
+	<-p.slots
+
+	p.claim(models[1])
+	if result, err := p.Solve(context.Background(), models[1]); !errors.Is(err, ErrNotSolved) || result.Status != NotSolved {
+		t.Errorf("got %+v, %v, want ErrNotSolved for a model the pool is solving", result, err)
+	}
+	p.release(models[1])
+
+	// the repeated model is solved once or twice, one after the other, but never at the same time
+	results, errs = p.SolveAll(context.Background(), []*Model{models[1], models[2], models[1]})
+	for i, want := range []float64{1, 2, 1} {
+		if errs[i] == nil && results[i].ObjectiveValue != want || errs[i] != nil && (!errors.Is(errs[i], ErrNotSolved) || i == 1) {
+			t.Errorf("model %d: got %+v, %v, want the objective %v or ErrNotSolved for a repeated model", i, results[i], errs[i], want)
+		}
+	}
+}
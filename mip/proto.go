@@ -0,0 +1,956 @@
+package mip
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// This file converts models to and from OR-Tools' MPModelProto, and solutions to and from MPSolutionResponse,
+// as defined in ortools/linear_solver/linear_solver.proto. Both the binary wire format and the protojson format
+// are supported, so that models built in Go can be replayed with the OR-Tools tools or from Python.
+// The encoding is done by hand to keep the package free of protobuf dependencies.
+
+// ProtoFormat is the encoding of a protocol buffer message.
+type ProtoFormat int
+
+const (
+	ProtoBinary ProtoFormat = iota // the protobuf wire format
+	ProtoJSON                      // the protojson format
+)
+
+// field numbers, from linear_solver.proto
+const (
+	modelMaximize           = 1
+	modelObjectiveOffset    = 2
+	modelVariable           = 3
+	modelConstraint         = 4
+	modelName               = 5
+	modelSolutionHint       = 6
+	modelGeneralConstraint  = 7
+	modelQuadraticObjective = 8
+
+	variableLowerBound           = 1
+	variableUpperBound           = 2
+	variableObjectiveCoefficient = 3
+	variableIsInteger            = 4
+	variableName                 = 5
+
+	generalConstraintName      = 1
+	generalConstraintIndicator = 2
+	generalConstraintSOS       = 3
+
+	indicatorVarIndex   = 1
+	indicatorVarValue   = 2
+	indicatorConstraint = 3
+
+	sosType     = 1
+	sosVarIndex = 2
+	sosWeight   = 3
+
+	constraintLowerBound  = 2
+	constraintUpperBound  = 3
+	constraintName        = 4
+	constraintVarIndex    = 6
+	constraintCoefficient = 7
+
+	responseStatus             = 1
+	responseObjectiveValue     = 2
+	responseVariableValue      = 3
+	responseDualValue          = 4
+	responseBestObjectiveBound = 5
+	responseReducedCost        = 6
+	responseStatusStr          = 7
+)
+
+// protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// protoVariable, protoConstraint, protoGeneralConstraint, protoIndicatorConstraint, protoSOSConstraint and protoModel
+// mirror MPVariableProto, MPConstraintProto, MPGeneralConstraintProto, MPIndicatorConstraint, MPSosConstraint
+// and MPModelProto.
+type protoVariable struct {
+	LowerBound           jsonFloat `json:"lowerBound"`
+	UpperBound           jsonFloat `json:"upperBound"`
+	ObjectiveCoefficient jsonFloat `json:"objectiveCoefficient,omitempty"`
+	IsInteger            bool      `json:"isInteger,omitempty"`
+	Name                 string    `json:"name,omitempty"`
+}
+
+type protoConstraint struct {
+	VarIndex    []int32     `json:"varIndex,omitempty"`
+	Coefficient []jsonFloat `json:"coefficient,omitempty"`
+	LowerBound  jsonFloat   `json:"lowerBound"`
+	UpperBound  jsonFloat   `json:"upperBound"`
+	Name        string      `json:"name,omitempty"`
+}
+
+type protoGeneralConstraint struct {
+	Name                string                    `json:"name,omitempty"`
+	IndicatorConstraint *protoIndicatorConstraint `json:"indicatorConstraint,omitempty"`
+	SOSConstraint       *protoSOSConstraint       `json:"sosConstraint,omitempty"`
+}
+
+type protoIndicatorConstraint struct {
+	VarIndex   int32           `json:"varIndex"`
+	VarValue   int32           `json:"varValue,omitempty"`
+	Constraint protoConstraint `json:"constraint"`
+}
+
+type protoSOSConstraint struct {
+	Type     protoSOSType `json:"type,omitempty"`
+	VarIndex []int32      `json:"varIndex,omitempty"`
+	Weight   []jsonFloat  `json:"weight,omitempty"`
+}
+
+// protoSOSType is MPSosConstraint.Type, 0 for SOS1 and 1 for SOS2, written by name in JSON.
+type protoSOSType int32
+
+var protoSOSTypeNames = []string{"SOS1_DEFAULT", "SOS2"}
+
+func (t protoSOSType) MarshalJSON() ([]byte, error) {
+	if t >= 0 && int(t) < len(protoSOSTypeNames) {
+		return json.Marshal(protoSOSTypeNames[t])
+	}
+	return json.Marshal(int32(t))
+}
+
+func (t *protoSOSType) UnmarshalJSON(data []byte) error {
+	var name string
+	if json.Unmarshal(data, &name) == nil {
+		i := slices.Index(protoSOSTypeNames, name)
+		if i < 0 {
+			return fmt.Errorf("invalid SOS type %q", name)
+		}
+		*t = protoSOSType(i)
+		return nil
+	}
+	return json.Unmarshal(data, (*int32)(t))
+}
+
+type protoModel struct {
+	Maximize          bool                     `json:"maximize,omitempty"`
+	ObjectiveOffset   jsonFloat                `json:"objectiveOffset,omitempty"`
+	Variable          []protoVariable          `json:"variable,omitempty"`
+	Constraint        []protoConstraint        `json:"constraint,omitempty"`
+	GeneralConstraint []protoGeneralConstraint `json:"generalConstraint,omitempty"`
+}
+
+// ExportProto encodes the Model as an MPModelProto.
+// Constraint terms are sorted by variable index, so the same model always produces the same bytes.
+func (m *Model) ExportProto(format ProtoFormat) ([]byte, error) {
+	objective := m.fullObjective()
+	pm := protoModel{Maximize: m.sense == Maximize, ObjectiveOffset: jsonFloat(objective.constant)}
+	for _, v := range m.variables {
+		pm.Variable = append(pm.Variable, protoVariable{
+			LowerBound:           jsonFloat(v.lowerBound),
+			UpperBound:           jsonFloat(v.upperBound),
+			ObjectiveCoefficient: jsonFloat(objective.terms[v]),
+			IsInteger:            v.integer,
+			Name:                 v.name,
+		})
+	}
+	for _, c := range m.constraints {
+		pm.Constraint = append(pm.Constraint, newProtoConstraint(c))
+	}
+	for _, ic := range m.indicators {
+		pm.GeneralConstraint = append(pm.GeneralConstraint, protoGeneralConstraint{
+			IndicatorConstraint: &protoIndicatorConstraint{
+				VarIndex:   int32(ic.indicator.index),
+				VarValue:   int32(ic.activeValue()),
+				Constraint: newProtoConstraint(ic.row()),
+			},
+		})
+	}
+	for _, sos := range m.sos {
+		ps := &protoSOSConstraint{Type: protoSOSType(sos.sosType - SOS1)}
+		for i, v := range sos.vars {
+			ps.VarIndex = append(ps.VarIndex, int32(v.index))
+			ps.Weight = append(ps.Weight, jsonFloat(sos.weights[i]))
+		}
+		pm.GeneralConstraint = append(pm.GeneralConstraint, protoGeneralConstraint{SOSConstraint: ps})
+	}
+
+	switch format {
+	case ProtoBinary:
+		return pm.marshal(), nil
+	case ProtoJSON:
+		return json.Marshal(pm)
+	default:
+		return nil, fmt.Errorf("unknown proto format %d", format)
+	}
+}
+
+func newProtoConstraint(c *Constraint) protoConstraint {
+	pc := protoConstraint{LowerBound: jsonFloat(c.lowerBound), UpperBound: jsonFloat(c.upperBound), Name: c.name}
+	for _, v := range c.expr.Vars() {
+		pc.VarIndex = append(pc.VarIndex, int32(v.index))
+		pc.Coefficient = append(pc.Coefficient, jsonFloat(c.expr.terms[v]))
+	}
+	return pc
+}
+
+// LoadProto decodes an MPModelProto into a new Model.
+// Missing bounds take the proto defaults, -inf and +inf, and solution hints are ignored. Indicator constraints
+// and special ordered sets are the only supported general constraints, quadratic objectives are not supported.
+func LoadProto(data []byte, format ProtoFormat) (*Model, error) {
+	var pm protoModel
+	var err error
+	switch format {
+	case ProtoBinary:
+		err = pm.unmarshal(data)
+	case ProtoJSON:
+		err = pm.unmarshalJSON(data)
+	default:
+		err = fmt.Errorf("unknown proto format %d", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid MPModelProto: %w", err)
+	}
+
+	m := NewModel()
+	objective := NewLinearExpression()
+	for _, pv := range pm.Variable {
+		v := m.newVariable(pv.Name, float64(pv.LowerBound), float64(pv.UpperBound), pv.IsInteger)
+		if pv.ObjectiveCoefficient != 0 {
+			objective.AddTerm(v, float64(pv.ObjectiveCoefficient))
+		}
+	}
+	for i, pc := range pm.Constraint {
+		expr, err := pc.expression(m, fmt.Sprintf("constraint %d", i))
+		if err != nil {
+			return nil, err
+		}
+		m.addConstraint(pc.Name, float64(pc.LowerBound), float64(pc.UpperBound), expr)
+	}
+	for i, pg := range pm.GeneralConstraint {
+		if ps := pg.SOSConstraint; ps != nil {
+			if err := ps.add(m, i); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		pi := pg.IndicatorConstraint
+		if pi.VarIndex < 0 || int(pi.VarIndex) >= len(m.variables) {
+			return nil, fmt.Errorf("invalid MPModelProto: general constraint %d refers to unknown variable %d", i, pi.VarIndex)
+		}
+		expr, err := pi.Constraint.expression(m, fmt.Sprintf("general constraint %d", i))
+		if err != nil {
+			return nil, err
+		}
+		pc := pi.Constraint
+		m.addIndicatorConstraint(m.variables[pi.VarIndex], pi.VarValue != 0, float64(pc.LowerBound), float64(pc.UpperBound), expr)
+	}
+
+	sense := Minimize
+	if pm.Maximize {
+		sense = Maximize
+	}
+	objective.AddConstant(float64(pm.ObjectiveOffset))
+	m.SetObjective(objective, sense)
+	return m, nil
+}
+
+// add adds the special ordered set to the Model, i is its index among the general constraints.
+// Missing weights mean 1, 2, ... in the order of the variables.
+func (ps *protoSOSConstraint) add(m *Model, i int) error {
+	if ps.Type != 0 && ps.Type != 1 {
+		return fmt.Errorf("invalid MPModelProto: general constraint %d has the unknown SOS type %d", i, ps.Type)
+	}
+	if len(ps.Weight) != 0 && len(ps.Weight) != len(ps.VarIndex) {
+		return fmt.Errorf("invalid MPModelProto: general constraint %d has %d variables and %d weights", i, len(ps.VarIndex), len(ps.Weight))
+	}
+	vars := make([]*Variable, len(ps.VarIndex))
+	for j, index := range ps.VarIndex {
+		if index < 0 || int(index) >= len(m.variables) {
+			return fmt.Errorf("invalid MPModelProto: general constraint %d refers to unknown variable %d", i, index)
+		}
+		vars[j] = m.variables[index]
+	}
+	var weights []float64
+	if len(ps.Weight) != 0 {
+		weights = fromJSONFloats(ps.Weight)
+	}
+	m.addSOS(SOS1+SOSType(ps.Type), vars, weights)
+	return nil
+}
+
+// expression returns the linear expression of the constraint, in terms of the variables of the Model.
+func (pc protoConstraint) expression(m *Model, what string) (*LinearExpression, error) {
+	if len(pc.VarIndex) != len(pc.Coefficient) {
+		return nil, fmt.Errorf("invalid MPModelProto: %s has %d variables and %d coefficients", what, len(pc.VarIndex), len(pc.Coefficient))
+	}
+	expr := NewLinearExpression()
+	for j, index := range pc.VarIndex {
+		if index < 0 || int(index) >= len(m.variables) {
+			return nil, fmt.Errorf("invalid MPModelProto: %s refers to unknown variable %d", what, index)
+		}
+		expr.AddTerm(m.variables[index], float64(pc.Coefficient[j]))
+	}
+	return expr, nil
+}
+
+// SolutionResponse mirrors OR-Tools' MPSolutionResponse.
+// Values are indexed like the variables and constraints of the solved Model.
+type SolutionResponse struct {
+	Status         ResultStatus
+	StatusString   string
+	ObjectiveValue float64
+	BestBound      float64
+	VariableValues []float64
+	DualValues     []float64
+	ReducedCosts   []float64
+}
+
+// SolutionResponse returns the last solution of the Solver as a SolutionResponse.
+func (s *Solver) SolutionResponse() SolutionResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := SolutionResponse{
+		Status:         s.lastResult.Status,
+		StatusString:   s.lastResult.Status.String(),
+		ObjectiveValue: s.lastResult.ObjectiveValue,
+		BestBound:      s.lastResult.BestBound,
+	}
+	if !s.lastResult.HasSolution() {
+		return r
+	}
+
+	for _, v := range s.variables {
+		r.VariableValues = append(r.VariableValues, v.value)
+		r.ReducedCosts = append(r.ReducedCosts, v.reducedCost)
+	}
+	for _, c := range s.constraints {
+		r.DualValues = append(r.DualValues, c.dualValue)
+	}
+	return r
+}
+
+// statuses of MPSolverResponseStatus, by ResultStatus
+var responseStatuses = map[ResultStatus]struct {
+	value int
+	name  string
+}{
+	Optimal:      {0, "MPSOLVER_OPTIMAL"},
+	Feasible:     {1, "MPSOLVER_FEASIBLE"},
+	Infeasible:   {2, "MPSOLVER_INFEASIBLE"},
+	Unbounded:    {3, "MPSOLVER_UNBOUNDED"},
+	Abnormal:     {4, "MPSOLVER_ABNORMAL"},
+	ModelInvalid: {5, "MPSOLVER_MODEL_INVALID"},
+	NotSolved:    {6, "MPSOLVER_NOT_SOLVED"},
+	Cancelled:    {98, "MPSOLVER_CANCELLED_BY_USER"},
+}
+
+const unknownResponseStatus = 99 // MPSOLVER_UNKNOWN_STATUS
+
+// Export encodes the SolutionResponse as an MPSolutionResponse.
+func (r SolutionResponse) Export(format ProtoFormat) ([]byte, error) {
+	status, ok := responseStatuses[r.Status]
+	if !ok {
+		status.value, status.name = unknownResponseStatus, "MPSOLVER_UNKNOWN_STATUS"
+	}
+
+	switch format {
+	case ProtoBinary:
+		var b []byte
+		b = appendVarintField(b, responseStatus, uint64(status.value))
+		b = appendDoubleField(b, responseObjectiveValue, r.ObjectiveValue)
+		b = appendPackedDoubles(b, responseVariableValue, r.VariableValues)
+		b = appendPackedDoubles(b, responseDualValue, r.DualValues)
+		b = appendDoubleField(b, responseBestObjectiveBound, r.BestBound)
+		b = appendPackedDoubles(b, responseReducedCost, r.ReducedCosts)
+		b = appendStringField(b, responseStatusStr, r.StatusString)
+		return b, nil
+	case ProtoJSON:
+		return json.Marshal(struct {
+			Status             string      `json:"status"`
+			StatusStr          string      `json:"statusStr,omitempty"`
+			ObjectiveValue     jsonFloat   `json:"objectiveValue"`
+			BestObjectiveBound jsonFloat   `json:"bestObjectiveBound"`
+			VariableValue      []jsonFloat `json:"variableValue,omitempty"`
+			DualValue          []jsonFloat `json:"dualValue,omitempty"`
+			ReducedCost        []jsonFloat `json:"reducedCost,omitempty"`
+		}{
+			status.name, r.StatusString, jsonFloat(r.ObjectiveValue), jsonFloat(r.BestBound),
+			toJSONFloats(r.VariableValues), toJSONFloats(r.DualValues), toJSONFloats(r.ReducedCosts),
+		})
+	default:
+		return nil, fmt.Errorf("unknown proto format %d", format)
+	}
+}
+
+// LoadSolutionResponse decodes an MPSolutionResponse, e.g. produced by the OR-Tools solve tool.
+// Statuses without a ResultStatus equivalent are decoded as Abnormal.
+func LoadSolutionResponse(data []byte, format ProtoFormat) (SolutionResponse, error) {
+	var r SolutionResponse
+	statusValue := unknownResponseStatus
+	var statusName string
+
+	switch format {
+	case ProtoBinary:
+		err := walkFields(data, func(field int, wireType int, value uint64, bytes []byte) error {
+			var err error
+			switch field {
+			case responseStatus:
+				statusValue = int(int32(value))
+			case responseObjectiveValue:
+				r.ObjectiveValue = math.Float64frombits(value)
+			case responseBestObjectiveBound:
+				r.BestBound = math.Float64frombits(value)
+			case responseStatusStr:
+				r.StatusString = string(bytes)
+			case responseVariableValue:
+				r.VariableValues, err = appendDoubles(r.VariableValues, wireType, value, bytes)
+			case responseDualValue:
+				r.DualValues, err = appendDoubles(r.DualValues, wireType, value, bytes)
+			case responseReducedCost:
+				r.ReducedCosts, err = appendDoubles(r.ReducedCosts, wireType, value, bytes)
+			}
+			return err
+		})
+		if err != nil {
+			return r, fmt.Errorf("invalid MPSolutionResponse: %w", err)
+		}
+	case ProtoJSON:
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return r, fmt.Errorf("invalid MPSolutionResponse: %w", err)
+		}
+		var objectiveValue, bestBound jsonFloat
+		var variableValues, dualValues, reducedCosts []jsonFloat
+		var status json.RawMessage
+		err := errors.Join(
+			jsonField(fields, "status", "status", &status),
+			jsonField(fields, "statusStr", "status_str", &r.StatusString),
+			jsonField(fields, "objectiveValue", "objective_value", &objectiveValue),
+			jsonField(fields, "bestObjectiveBound", "best_objective_bound", &bestBound),
+			jsonField(fields, "variableValue", "variable_value", &variableValues),
+			jsonField(fields, "dualValue", "dual_value", &dualValues),
+			jsonField(fields, "reducedCost", "reduced_cost", &reducedCosts),
+		)
+		if err != nil {
+			return r, fmt.Errorf("invalid MPSolutionResponse: %w", err)
+		}
+		if status != nil && json.Unmarshal(status, &statusName) != nil {
+			if err := json.Unmarshal(status, &statusValue); err != nil {
+				return r, fmt.Errorf("invalid MPSolutionResponse: invalid status %s", status)
+			}
+		}
+		r.ObjectiveValue, r.BestBound = float64(objectiveValue), float64(bestBound)
+		r.VariableValues, r.DualValues, r.ReducedCosts = fromJSONFloats(variableValues), fromJSONFloats(dualValues), fromJSONFloats(reducedCosts)
+	default:
+		return r, fmt.Errorf("unknown proto format %d", format)
+	}
+
+	r.Status = Abnormal
+	for status, s := range responseStatuses {
+		if s.value == statusValue || s.name == statusName {
+			r.Status = status
+		}
+	}
+	return r, nil
+}
+
+func (pm protoModel) marshal() []byte {
+	var b []byte
+	if pm.Maximize {
+		b = appendVarintField(b, modelMaximize, 1)
+	}
+	if pm.ObjectiveOffset != 0 {
+		b = appendDoubleField(b, modelObjectiveOffset, float64(pm.ObjectiveOffset))
+	}
+	for _, pv := range pm.Variable {
+		var vb []byte
+		vb = appendDoubleField(vb, variableLowerBound, float64(pv.LowerBound))
+		vb = appendDoubleField(vb, variableUpperBound, float64(pv.UpperBound))
+		if pv.ObjectiveCoefficient != 0 {
+			vb = appendDoubleField(vb, variableObjectiveCoefficient, float64(pv.ObjectiveCoefficient))
+		}
+		if pv.IsInteger {
+			vb = appendVarintField(vb, variableIsInteger, 1)
+		}
+		if pv.Name != "" {
+			vb = appendStringField(vb, variableName, pv.Name)
+		}
+		b = appendBytesField(b, modelVariable, vb)
+	}
+	for _, pc := range pm.Constraint {
+		b = appendBytesField(b, modelConstraint, pc.marshal())
+	}
+	for _, pg := range pm.GeneralConstraint {
+		var gb []byte
+		if pg.Name != "" {
+			gb = appendStringField(gb, generalConstraintName, pg.Name)
+		}
+		if pi := pg.IndicatorConstraint; pi != nil {
+			var ib []byte
+			ib = appendVarintField(ib, indicatorVarIndex, uint64(pi.VarIndex))
+			if pi.VarValue != 0 {
+				ib = appendVarintField(ib, indicatorVarValue, uint64(pi.VarValue))
+			}
+			ib = appendBytesField(ib, indicatorConstraint, pi.Constraint.marshal())
+			gb = appendBytesField(gb, generalConstraintIndicator, ib)
+		}
+		if ps := pg.SOSConstraint; ps != nil {
+			var sb []byte
+			if ps.Type != 0 {
+				sb = appendVarintField(sb, sosType, uint64(ps.Type))
+			}
+			sb = appendPackedInt32s(sb, sosVarIndex, ps.VarIndex)
+			sb = appendPackedDoubles(sb, sosWeight, fromJSONFloats(ps.Weight))
+			gb = appendBytesField(gb, generalConstraintSOS, sb)
+		}
+		b = appendBytesField(b, modelGeneralConstraint, gb)
+	}
+	return b
+}
+
+func (pc protoConstraint) marshal() []byte {
+	var b []byte
+	b = appendDoubleField(b, constraintLowerBound, float64(pc.LowerBound))
+	b = appendDoubleField(b, constraintUpperBound, float64(pc.UpperBound))
+	if pc.Name != "" {
+		b = appendStringField(b, constraintName, pc.Name)
+	}
+	b = appendPackedInt32s(b, constraintVarIndex, pc.VarIndex)
+	b = appendPackedDoubles(b, constraintCoefficient, fromJSONFloats(pc.Coefficient))
+	return b
+}
+
+func (pm *protoModel) unmarshal(data []byte) error {
+	return walkFields(data, func(field int, wireType int, value uint64, bytes []byte) error {
+		switch field {
+		case modelMaximize:
+			pm.Maximize = value != 0
+		case modelObjectiveOffset:
+			pm.ObjectiveOffset = jsonFloat(math.Float64frombits(value))
+		case modelVariable:
+			pv := protoVariable{LowerBound: jsonFloat(math.Inf(-1)), UpperBound: jsonFloat(math.Inf(1))}
+			err := walkFields(bytes, func(field int, _ int, value uint64, bytes []byte) error {
+				switch field {
+				case variableLowerBound:
+					pv.LowerBound = jsonFloat(math.Float64frombits(value))
+				case variableUpperBound:
+					pv.UpperBound = jsonFloat(math.Float64frombits(value))
+				case variableObjectiveCoefficient:
+					pv.ObjectiveCoefficient = jsonFloat(math.Float64frombits(value))
+				case variableIsInteger:
+					pv.IsInteger = value != 0
+				case variableName:
+					pv.Name = string(bytes)
+				}
+				return nil
+			})
+			pm.Variable = append(pm.Variable, pv)
+			return err
+		case modelConstraint:
+			pc, err := unmarshalProtoConstraint(bytes)
+			pm.Constraint = append(pm.Constraint, pc)
+			return err
+		case modelGeneralConstraint:
+			pg, err := unmarshalProtoGeneralConstraint(bytes)
+			pm.GeneralConstraint = append(pm.GeneralConstraint, pg)
+			return err
+		case modelQuadraticObjective:
+			return fmt.Errorf("quadratic objectives are not supported")
+		}
+		return nil
+	})
+}
+
+func unmarshalProtoConstraint(data []byte) (protoConstraint, error) {
+	pc := protoConstraint{LowerBound: jsonFloat(math.Inf(-1)), UpperBound: jsonFloat(math.Inf(1))}
+	var coefficients []float64
+	err := walkFields(data, func(field int, wireType int, value uint64, bytes []byte) error {
+		var err error
+		switch field {
+		case constraintLowerBound:
+			pc.LowerBound = jsonFloat(math.Float64frombits(value))
+		case constraintUpperBound:
+			pc.UpperBound = jsonFloat(math.Float64frombits(value))
+		case constraintName:
+			pc.Name = string(bytes)
+		case constraintVarIndex:
+			pc.VarIndex, err = appendInt32s(pc.VarIndex, wireType, value, bytes)
+		case constraintCoefficient:
+			coefficients, err = appendDoubles(coefficients, wireType, value, bytes)
+		}
+		return err
+	})
+	pc.Coefficient = toJSONFloats(coefficients)
+	return pc, err
+}
+
+// unmarshalProtoGeneralConstraint decodes an MPGeneralConstraintProto, which must be an indicator constraint
+// or a special ordered set.
+func unmarshalProtoGeneralConstraint(data []byte) (protoGeneralConstraint, error) {
+	var pg protoGeneralConstraint
+	err := walkFields(data, func(field int, _ int, _ uint64, bytes []byte) error {
+		switch field {
+		case generalConstraintName:
+			pg.Name = string(bytes)
+			return nil
+		case generalConstraintIndicator:
+			pi := &protoIndicatorConstraint{Constraint: protoConstraint{LowerBound: jsonFloat(math.Inf(-1)), UpperBound: jsonFloat(math.Inf(1))}}
+			pg.IndicatorConstraint = pi
+			return walkFields(bytes, func(field int, _ int, value uint64, bytes []byte) error {
+				var err error
+				switch field {
+				case indicatorVarIndex:
+					pi.VarIndex = int32(value)
+				case indicatorVarValue:
+					pi.VarValue = int32(value)
+				case indicatorConstraint:
+					pi.Constraint, err = unmarshalProtoConstraint(bytes)
+				}
+				return err
+			})
+		case generalConstraintSOS:
+			ps := &protoSOSConstraint{}
+			pg.SOSConstraint = ps
+			var weights []float64
+			err := walkFields(bytes, func(field int, wireType int, value uint64, bytes []byte) error {
+				var err error
+				switch field {
+				case sosType:
+					ps.Type = protoSOSType(value)
+				case sosVarIndex:
+					ps.VarIndex, err = appendInt32s(ps.VarIndex, wireType, value, bytes)
+				case sosWeight:
+					weights, err = appendDoubles(weights, wireType, value, bytes)
+				}
+				return err
+			})
+			ps.Weight = toJSONFloats(weights)
+			return err
+		default:
+			return fmt.Errorf("general constraints other than indicator constraints and special ordered sets are not supported")
+		}
+	})
+	if err == nil && pg.IndicatorConstraint == nil && pg.SOSConstraint == nil {
+		err = fmt.Errorf("general constraint without a constraint")
+	}
+	return pg, err
+}
+
+func (pm *protoModel) unmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	var variables, constraints, generalConstraints []map[string]json.RawMessage
+	err := errors.Join(
+		jsonField(fields, "maximize", "maximize", &pm.Maximize),
+		jsonField(fields, "objectiveOffset", "objective_offset", &pm.ObjectiveOffset),
+		jsonField(fields, "variable", "variable", &variables),
+		jsonField(fields, "constraint", "constraint", &constraints),
+		jsonField(fields, "generalConstraint", "general_constraint", &generalConstraints),
+	)
+	if err != nil {
+		return err
+	}
+	if fields["quadraticObjective"] != nil || fields["quadratic_objective"] != nil {
+		return fmt.Errorf("quadratic objectives are not supported")
+	}
+
+	for _, vf := range variables {
+		pv := protoVariable{LowerBound: jsonFloat(math.Inf(-1)), UpperBound: jsonFloat(math.Inf(1))}
+		err := errors.Join(
+			jsonField(vf, "lowerBound", "lower_bound", &pv.LowerBound),
+			jsonField(vf, "upperBound", "upper_bound", &pv.UpperBound),
+			jsonField(vf, "objectiveCoefficient", "objective_coefficient", &pv.ObjectiveCoefficient),
+			jsonField(vf, "isInteger", "is_integer", &pv.IsInteger),
+			jsonField(vf, "name", "name", &pv.Name),
+		)
+		if err != nil {
+			return err
+		}
+		pm.Variable = append(pm.Variable, pv)
+	}
+	for _, cf := range constraints {
+		pc, err := protoConstraintFromJSON(cf)
+		if err != nil {
+			return err
+		}
+		pm.Constraint = append(pm.Constraint, pc)
+	}
+	for _, gf := range generalConstraints {
+		var pg protoGeneralConstraint
+		var indicator, sos map[string]json.RawMessage
+		err := errors.Join(
+			jsonField(gf, "name", "name", &pg.Name),
+			jsonField(gf, "indicatorConstraint", "indicator_constraint", &indicator),
+			jsonField(gf, "sosConstraint", "sos_constraint", &sos),
+		)
+		if err != nil {
+			return err
+		}
+		if sos != nil {
+			ps := &protoSOSConstraint{}
+			err := errors.Join(
+				jsonField(sos, "type", "type", &ps.Type),
+				jsonField(sos, "varIndex", "var_index", &ps.VarIndex),
+				jsonField(sos, "weight", "weight", &ps.Weight),
+			)
+			if err != nil {
+				return err
+			}
+			pg.SOSConstraint = ps
+			pm.GeneralConstraint = append(pm.GeneralConstraint, pg)
+			continue
+		}
+		if indicator == nil {
+			return fmt.Errorf("general constraints other than indicator constraints and special ordered sets are not supported")
+		}
+		pi := &protoIndicatorConstraint{}
+		var constraint map[string]json.RawMessage
+		err = errors.Join(
+			jsonField(indicator, "varIndex", "var_index", &pi.VarIndex),
+			jsonField(indicator, "varValue", "var_value", &pi.VarValue),
+			jsonField(indicator, "constraint", "constraint", &constraint),
+		)
+		if err != nil {
+			return err
+		}
+		if pi.Constraint, err = protoConstraintFromJSON(constraint); err != nil {
+			return err
+		}
+		pg.IndicatorConstraint = pi
+		pm.GeneralConstraint = append(pm.GeneralConstraint, pg)
+	}
+	return nil
+}
+
+func protoConstraintFromJSON(cf map[string]json.RawMessage) (protoConstraint, error) {
+	pc := protoConstraint{LowerBound: jsonFloat(math.Inf(-1)), UpperBound: jsonFloat(math.Inf(1))}
+	err := errors.Join(
+		jsonField(cf, "varIndex", "var_index", &pc.VarIndex),
+		jsonField(cf, "coefficient", "coefficient", &pc.Coefficient),
+		jsonField(cf, "lowerBound", "lower_bound", &pc.LowerBound),
+		jsonField(cf, "upperBound", "upper_bound", &pc.UpperBound),
+		jsonField(cf, "name", "name", &pc.Name),
+	)
+	return pc, err
+}
+
+// jsonField decodes the field of a protojson object, which may use the JSON name or the original proto name.
+func jsonField(fields map[string]json.RawMessage, jsonName, protoName string, dst any) error {
+	raw, ok := fields[jsonName]
+	if !ok {
+		raw, ok = fields[protoName]
+	}
+	if !ok || string(raw) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return fmt.Errorf("field %s: %w", jsonName, err)
+	}
+	return nil
+}
+
+// jsonFloat is a float64 encoded like protojson does: infinities and NaN as strings, and numbers also read from strings.
+type jsonFloat float64
+
+func (f jsonFloat) MarshalJSON() ([]byte, error) {
+	x := float64(f)
+	switch {
+	case math.IsInf(x, 1):
+		return []byte(`"Infinity"`), nil
+	case math.IsInf(x, -1):
+		return []byte(`"-Infinity"`), nil
+	case math.IsNaN(x):
+		return []byte(`"NaN"`), nil
+	default:
+		return strconv.AppendFloat(nil, x, 'g', -1, 64), nil
+	}
+}
+
+func (f *jsonFloat) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		switch s {
+		case "Infinity":
+			*f = jsonFloat(math.Inf(1))
+		case "-Infinity":
+			*f = jsonFloat(math.Inf(-1))
+		case "NaN":
+			*f = jsonFloat(math.NaN())
+		default:
+			x, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return fmt.Errorf("invalid number %q", s)
+			}
+			*f = jsonFloat(x)
+		}
+		return nil
+	}
+
+	var x float64
+	if err := json.Unmarshal(data, &x); err != nil {
+		return err
+	}
+	*f = jsonFloat(x)
+	return nil
+}
+
+func toJSONFloats(xs []float64) []jsonFloat {
+	if xs == nil {
+		return nil
+	}
+	fs := make([]jsonFloat, len(xs))
+	for i, x := range xs {
+		fs[i] = jsonFloat(x)
+	}
+	return fs
+}
+
+func fromJSONFloats(fs []jsonFloat) []float64 {
+	if fs == nil {
+		return nil
+	}
+	xs := make([]float64, len(fs))
+	for i, f := range fs {
+		xs[i] = float64(f)
+	}
+	return xs
+}
+
+// protobuf wire format encoding
+
+func appendTag(b []byte, field, wireType int) []byte {
+	return binary.AppendUvarint(b, uint64(field)<<3|uint64(wireType))
+}
+
+func appendVarintField(b []byte, field int, value uint64) []byte {
+	return binary.AppendUvarint(appendTag(b, field, wireVarint), value)
+}
+
+func appendDoubleField(b []byte, field int, value float64) []byte {
+	return binary.LittleEndian.AppendUint64(appendTag(b, field, wireFixed64), math.Float64bits(value))
+}
+
+func appendBytesField(b []byte, field int, value []byte) []byte {
+	b = binary.AppendUvarint(appendTag(b, field, wireBytes), uint64(len(value)))
+	return append(b, value...)
+}
+
+func appendStringField(b []byte, field int, value string) []byte {
+	return appendBytesField(b, field, []byte(value))
+}
+
+func appendPackedDoubles(b []byte, field int, values []float64) []byte {
+	if len(values) == 0 {
+		return b
+	}
+	packed := make([]byte, 0, 8*len(values))
+	for _, x := range values {
+		packed = binary.LittleEndian.AppendUint64(packed, math.Float64bits(x))
+	}
+	return appendBytesField(b, field, packed)
+}
+
+func appendPackedInt32s(b []byte, field int, values []int32) []byte {
+	if len(values) == 0 {
+		return b
+	}
+	var packed []byte
+	for _, x := range values {
+		packed = binary.AppendUvarint(packed, uint64(int64(x))) // negative int32 are sign extended
+	}
+	return appendBytesField(b, field, packed)
+}
+
+// protobuf wire format decoding
+
+// walkFields calls f for each field of an encoded message: value holds varint and fixed values,
+// bytes holds length-delimited values.
+func walkFields(data []byte, f func(field int, wireType int, value uint64, bytes []byte) error) error {
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fmt.Errorf("truncated message")
+		}
+		data = data[n:]
+		field, wireType := int(tag>>3), int(tag&7)
+
+		var value uint64
+		var bytes []byte
+		switch wireType {
+		case wireVarint:
+			value, n = binary.Uvarint(data)
+			if n <= 0 {
+				return fmt.Errorf("truncated varint")
+			}
+			data = data[n:]
+		case wireFixed64:
+			if len(data) < 8 {
+				return fmt.Errorf("truncated fixed64")
+			}
+			value, data = binary.LittleEndian.Uint64(data), data[8:]
+		case wireFixed32:
+			if len(data) < 4 {
+				return fmt.Errorf("truncated fixed32")
+			}
+			value, data = uint64(binary.LittleEndian.Uint32(data)), data[4:]
+		case wireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return fmt.Errorf("truncated length-delimited field")
+			}
+			bytes, data = data[n:n+int(length)], data[n+int(length):]
+		default:
+			return fmt.Errorf("unsupported wire type %d", wireType)
+		}
+
+		if err := f(field, wireType, value, bytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendDoubles decodes a repeated double field, packed or not.
+func appendDoubles(xs []float64, wireType int, value uint64, bytes []byte) ([]float64, error) {
+	switch wireType {
+	case wireFixed64:
+		return append(xs, math.Float64frombits(value)), nil
+	case wireBytes:
+		if len(bytes)%8 != 0 {
+			return nil, fmt.Errorf("invalid packed doubles")
+		}
+		for i := 0; i < len(bytes); i += 8 {
+			xs = append(xs, math.Float64frombits(binary.LittleEndian.Uint64(bytes[i:])))
+		}
+		return xs, nil
+	default:
+		return nil, fmt.Errorf("invalid wire type %d for doubles", wireType)
+	}
+}
+
+// appendInt32s decodes a repeated int32 field, packed or not.
+func appendInt32s(xs []int32, wireType int, value uint64, bytes []byte) ([]int32, error) {
+	switch wireType {
+	case wireVarint:
+		return append(xs, int32(value)), nil
+	case wireBytes:
+		for len(bytes) > 0 {
+			x, n := binary.Uvarint(bytes)
+			if n <= 0 {
+				return nil, fmt.Errorf("invalid packed varints")
+			}
+			xs, bytes = append(xs, int32(x)), bytes[n:]
+		}
+		return xs, nil
+	default:
+		return nil, fmt.Errorf("invalid wire type %d for int32", wireType)
+	}
+}
@@ -0,0 +1,129 @@
+package mip
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestProtoRoundTrip(t *testing.T) {
+	m := exportTestModel()
+	m.AddIndicatorConstraint(m.VariableByName("z"), true, sumOf(m.VariableByName("x")), LessThanOrEqual, 4)
+	m.AddSOS1([]*Variable{m.VariableByName("x"), m.VariableByName("y")}, []float64{1, 2})
+
+	for _, format := range []ProtoFormat{ProtoBinary, ProtoJSON} {
+		data, err := m.ExportProto(format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := LoadProto(data, format)
+		if err != nil {
+			t.Fatalf("format %d: %v", format, err)
+		}
+		again, err := loaded.ExportProto(format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, again) {
+			t.Errorf("format %d: got\n%s\nafter loading\n%s", format, again, data)
+		}
+		var want, got strings.Builder
+		m.WriteLP(&want)
+		loaded.WriteLP(&got)
+		if got.String() != want.String() {
+			t.Errorf("format %d: loaded\n%s\nwant\n%s", format, got.String(), want.String())
+		}
+	}
+}
+
+func TestLoadProtoBoundDefaults(t *testing.T) {
+	var variable, constraint, binary []byte
+	variable = appendDoubleField(variable, variableUpperBound, 5)
+	constraint = appendPackedInt32s(constraint, constraintVarIndex, []int32{0})
+	constraint = appendPackedDoubles(constraint, constraintCoefficient, []float64{1})
+	binary = appendBytesField(binary, modelVariable, variable)
+	binary = appendBytesField(binary, modelConstraint, constraint)
+
+	for format, data := range map[ProtoFormat][]byte{
+		ProtoBinary: binary,
+		ProtoJSON:   []byte(`{"variable":[{"upperBound":5}],"constraint":[{"varIndex":[0],"coefficient":[1]}]}`),
+	} {
+		m, err := LoadProto(data, format)
+		if err != nil {
+			t.Fatalf("format %d: %v", format, err)
+		}
+		v, c := m.Variables()[0], m.Constraints()[0]
+		if !math.IsInf(v.Lower(), -1) || v.Upper() != 5 || !math.IsInf(c.Lower(), -1) || !math.IsInf(c.Upper(), 1) {
+			t.Errorf("format %d: got variable [%v, %v] and constraint [%v, %v]", format, v.Lower(), v.Upper(), c.Lower(), c.Upper())
+		}
+	}
+}
+
+func TestLoadProtoErrors(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{`{"variable":[{}],"constraint":[{"varIndex":[1],"coefficient":[1]}]}`, "unknown variable 1"},
+		{`{"variable":[{}],"constraint":[{"varIndex":[0]}]}`, "1 variables and 0 coefficients"},
+		{`{"variable":[{}],"generalConstraint":[{"sosConstraint":{"type":"SOS3","varIndex":[0]}}]}`, "SOS3"},
+		{`{"variable":[{}],"generalConstraint":[{"indicatorConstraint":{"varIndex":2}}]}`, "unknown variable 2"},
+		{`{"variable":[`, "invalid MPModelProto"},
+	}
+	for _, test := range tests {
+		if _, err := LoadProto([]byte(test.data), ProtoJSON); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got %v, want %q", test.data, err, test.want)
+		}
+	}
+	if _, err := LoadProto([]byte{modelVariable<<3 | wireBytes, 10}, ProtoBinary); err == nil {
+		t.Error("no error for a truncated message")
+	}
+	if _, err := LoadProto(nil, ProtoFormat(7)); err == nil || !strings.Contains(err.Error(), "unknown proto format") {
+		t.Errorf("got %v for an unknown format", err)
+	}
+}
+
+func TestSolutionResponseRoundTrip(t *testing.T) {
+	r := SolutionResponse{
+		Status:         Feasible,
+		StatusString:   "FEASIBLE",
+		ObjectiveValue: 3.5,
+		BestBound:      4,
+		VariableValues: []float64{1, math.Inf(1)},
+		DualValues:     []float64{-1},
+		ReducedCosts:   []float64{0, 2},
+	}
+	for _, format := range []ProtoFormat{ProtoBinary, ProtoJSON} {
+		data, err := r.Export(format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := LoadSolutionResponse(data, format)
+		if err != nil {
+			t.Fatalf("format %d: %v", format, err)
+		}
+		if got.Status != r.Status || got.StatusString != r.StatusString || got.ObjectiveValue != r.ObjectiveValue ||
+			got.BestBound != r.BestBound || len(got.VariableValues) != 2 || !math.IsInf(got.VariableValues[1], 1) ||
+			len(got.DualValues) != 1 || got.ReducedCosts[1] != 2 {
+			t.Errorf("format %d: got %+v, want %+v", format, got, r)
+		}
+	}
+}
+
+func TestSolverSolutionResponse(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 3)
+	m.AddConstraintExpr(sumOf(x), LessThanOrEqual, 2)
+	m.SetObjective(sumOf(x), Maximize)
+	s, b := newTestSolver(m)
+	b.duals = true
+	if _, err := s.Solve(0); err != nil {
+		t.Fatal(err)
+	}
+	r := s.SolutionResponse()
+	if r.Status != Optimal || r.ObjectiveValue != 2 || len(r.VariableValues) != 1 || r.VariableValues[0] != 2 ||
+		len(r.DualValues) != 1 || r.DualValues[0] != 0.5 || len(r.ReducedCosts) != 1 {
+		t.Errorf("got %+v", r)
+	}
+}
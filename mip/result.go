@@ -0,0 +1,89 @@
+package mip
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// Errors returned by Solver.Solve when no solution was found, to be checked with errors.Is.
+var (
+	ErrInfeasible    = errors.New("the problem is infeasible")
+	ErrUnbounded     = errors.New("the problem is unbounded")
+	ErrAbnormal      = errors.New("the solver encountered an abnormal situation")
+	ErrModelInvalid  = errors.New("the model is invalid")
+	ErrNotSolved     = errors.New("the problem was not solved")
+	ErrCancelled     = errors.New("the solve was cancelled")
+	ErrUnknownStatus = errors.New("unknown result status")
+)
+
+// ErrReleased is returned when solving with a Solver after ReleaseResources.
+var ErrReleased = errors.New("the solver resources have been released")
+
+// ErrDuplicateName is returned when a name is given to two variables or two constraints of a Model.
+// AddNamedConstraint returns it without adding the constraint, while a duplicate variable name stays
+// the Model error, as a variable cannot be renamed.
+var ErrDuplicateName = errors.New("duplicate name")
+
+// SolveResult describes the outcome of a call to Solver.Solve.
+type SolveResult struct {
+	Status ResultStatus
+
+	// ObjectiveValue is the objective value of the best solution found, i.e. the primal bound.
+	ObjectiveValue float64
+	// BestBound is the best bound on the optimal objective value proven by the solver, i.e. the dual bound.
+	BestBound float64
+	// Gap is the relative gap between ObjectiveValue and BestBound, see Solver.Gap.
+	Gap float64
+
+	WallTime   time.Duration
+	Iterations int64 // number of simplex iterations
+	Nodes      int64 // number of branch-and-bound nodes, -1 if unknown
+
+	// HintAccepted reports whether the solution hint, see Solver.SetHint, was a complete feasible solution
+	// passed to a solver making use of hints. It is checked in Go against the Model, as the solvers do not report
+	// whether they started from the hint: it tells that the hint could be used, not that it was.
+	HintAccepted bool
+
+	solutionFound bool
+}
+
+// IsOptimal reports whether the solution found is proven optimal.
+func (r SolveResult) IsOptimal() bool { return r.Status == Optimal }
+
+// HasSolution reports whether a solution was found, optimal or not.
+// A cancelled solve may still have found a solution.
+func (r SolveResult) HasSolution() bool { return r.solutionFound }
+
+// err returns the sentinel error corresponding to the status, nil if a solution was found.
+func (r SolveResult) err() error {
+	switch r.Status {
+	case Optimal, Feasible:
+		return nil
+	case Infeasible:
+		return ErrInfeasible
+	case Unbounded:
+		return ErrUnbounded
+	case Abnormal:
+		return ErrAbnormal
+	case ModelInvalid:
+		return ErrModelInvalid
+	case NotSolved:
+		return ErrNotSolved
+	case Cancelled:
+		return ErrCancelled
+	default:
+		return ErrUnknownStatus
+	}
+}
+
+// relativeGap returns |bestBound - objVal| / |objVal|, 0 if they are equal and +Inf if they differ while objVal is 0.
+func relativeGap(objVal, bestBound float64) float64 {
+	switch {
+	case bestBound == objVal:
+		return 0
+	case objVal == 0:
+		return math.Inf(1)
+	}
+	return math.Abs((bestBound - objVal) / objVal)
+}
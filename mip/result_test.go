@@ -0,0 +1,66 @@
+package mip
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestRelativeGap(t *testing.T) {
+	tests := []struct {
+		objVal, bestBound, want float64
+	}{
+		{100, 90, 0.1},
+		{-50, -60, 0.2},
+		{0, 0, 0},
+		{0, -1, math.Inf(1)},
+		{10, math.Inf(1), math.Inf(1)},
+	}
+	for _, test := range tests {
+		if got := relativeGap(test.objVal, test.bestBound); !(got == test.want || math.Abs(got-test.want) < 1e-12) {
+			t.Errorf("relativeGap(%v, %v) = %v, want %v", test.objVal, test.bestBound, got, test.want)
+		}
+	}
+}
+
+func TestSolveResultOfZeroObjective(t *testing.T) {
+	m := NewModel()
+	m.VarInt("x", 0, 3)
+	s, _ := newTestSolver(m)
+	result, err := s.Solve(0)
+	if err != nil || result.ObjectiveValue != 0 || result.Gap != 0 || s.Gap() != 0 {
+		t.Errorf("got %+v, %v, want an optimal solution of value 0 with a gap of 0", result, err)
+	}
+}
+
+func TestSolveReturnsSentinelErrors(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 3)
+	e := NewLinearExpression()
+	e.AddVar(x)
+	m.AddConstraintExpr(e, GreaterThanOrEqual, 5)
+	s, _ := newTestSolver(m)
+	result, err := s.Solve(0)
+	if !errors.Is(err, ErrInfeasible) || result.Status != Infeasible || result.HasSolution() {
+		t.Errorf("got %+v, %v, want ErrInfeasible", result, err)
+	}
+
+	for status, want := range map[ResultStatus]error{
+		Optimal: nil, Feasible: nil, Unbounded: ErrUnbounded, Abnormal: ErrAbnormal, ModelInvalid: ErrModelInvalid,
+		NotSolved: ErrNotSolved, Cancelled: ErrCancelled, ResultStatus(42): ErrUnknownStatus,
+	} {
+		if err := (SolveResult{Status: status}).err(); err != want {
+			t.Errorf("%v: got %v, want %v", status, err, want)
+		}
+	}
+}
+
+func TestSolveFailsOnModelError(t *testing.T) {
+	m := NewModel()
+	m.VarFloat("x", 2, 1)
+	s, b := newTestSolver(m)
+	result, err := s.Solve(0)
+	if !errors.Is(err, ErrModelInvalid) || result.Status != NotSolved || b.solves != 0 {
+		t.Errorf("got %v, %v after %d solves, want ErrModelInvalid before solving", result.Status, err, b.solves)
+	}
+}
@@ -0,0 +1,122 @@
+package mip
+
+import (
+	"fmt"
+	"math"
+)
+
+// SoftConstraint is a Constraint that may be violated at a cost: slack variables absorb the violation,
+// and each unit of violation adds its penalty to the objective of the Model (or removes it when maximizing).
+type SoftConstraint struct {
+	*Constraint
+	under   *Variable // how much the expression is below its lower bound, nil for a <= constraint
+	over    *Variable // how much the expression is above its upper bound, nil for a >= constraint
+	penalty float64
+}
+
+// AddSoftConstraint adds a Constraint like AddConstraintExpr, which may be violated with the given penalty
+// per unit of violation. The penalties stay in the objective when it is replaced with SetObjective,
+// they are not part of the expression returned by Objective. It panics if the penalty is negative, infinite or NaN.
+func (m *Model) AddSoftConstraint(e *LinearExpression, t ConstraintType, rhs float64, penalty float64) *SoftConstraint {
+	lb, ub := constraintBounds(t, rhs)
+	return m.soften(m.addConstraint("", lb, ub, e), penalty)
+}
+
+// soften adds to the constraint the slack variables absorbing its violations, and their penalties to the Model.
+func (m *Model) soften(c *Constraint, penalty float64) *SoftConstraint {
+	if !(penalty >= 0) || math.IsInf(penalty, 1) {
+		panic(fmt.Sprintf("Invalid penalty for soft constraint %s: %v", c.label(), penalty))
+	}
+
+	sc := &SoftConstraint{Constraint: c, penalty: penalty}
+	if !math.IsInf(c.lowerBound, -1) {
+		sc.under = m.newVariable(m.slackName(c, "under"), 0, math.Inf(1), false)
+		c.expr.AddTerm(sc.under, 1)
+		m.penalties.AddTerm(sc.under, penalty)
+	}
+	if !math.IsInf(c.upperBound, 1) {
+		sc.over = m.newVariable(m.slackName(c, "over"), 0, math.Inf(1), false)
+		c.expr.AddTerm(sc.over, -1)
+		m.penalties.AddTerm(sc.over, penalty)
+	}
+	return sc
+}
+
+// slackName returns "<constraint>_<suffix>" for a named constraint if no variable has this name yet, "" otherwise.
+func (m *Model) slackName(c *Constraint, suffix string) string {
+	if c.name == "" || m.variablesByName[c.name+"_"+suffix] != nil {
+		return ""
+	}
+	return c.name + "_" + suffix
+}
+
+// Penalty returns the penalty per unit of violation of the constraint.
+func (sc *SoftConstraint) Penalty() float64 { return sc.penalty }
+
+// Violation returns by how much the constraint is violated in the most recent solution, 0 if it is satisfied.
+func (sc *SoftConstraint) Violation() float64 {
+	violation := 0.
+	if sc.under != nil {
+		violation += sc.under.value
+	}
+	if sc.over != nil {
+		violation += sc.over.value
+	}
+	if violation < feasibilityTolerance {
+		return 0
+	}
+	return violation
+}
+
+// fullObjective returns the objective of the Model including the penalties of the soft constraints.
+func (m *Model) fullObjective() *LinearExpression {
+	if len(m.penalties.terms) == 0 {
+		return m.objective
+	}
+	full := m.objective.Clone()
+	if m.sense == Maximize {
+		full.Sub(m.penalties)
+	} else {
+		full.AddExpr(m.penalties)
+	}
+	return full
+}
+
+// Relaxation is a copy of a Model where every constraint is soft, see Model.FeasibilityRelaxation.
+type Relaxation struct {
+	*Model
+	original *Model
+	soft     []*SoftConstraint // soft[i] relaxes the constraint i of the original Model
+}
+
+// Violation is a constraint of a Model violated by a solution of its Relaxation.
+type Violation struct {
+	Constraint *Constraint // the constraint of the original Model
+	Amount     float64     // how far the expression is from the bounds of the constraint
+}
+
+// FeasibilityRelaxation returns a copy of the Model where every constraint is soft with a penalty of 1,
+// and whose objective is to minimize the total violation. Variable bounds and integrality are not relaxed.
+// Solve the Relaxation with any Solver, e.g. NewSolverForModel(r.Model, CBC), then call Violations.
+func (m *Model) FeasibilityRelaxation() *Relaxation {
+	cp := m.Copy()
+	cp.SetObjective(NewLinearExpression(), Minimize)
+	cp.penalties = NewLinearExpression() // soft constraints of the Model are free to be violated
+	r := &Relaxation{Model: cp, original: m, soft: make([]*SoftConstraint, len(cp.constraints))}
+	for i, c := range cp.constraints {
+		r.soft[i] = cp.soften(c, 1)
+	}
+	return r
+}
+
+// Violations returns the constraints of the original Model violated by the most recent solution of the
+// Relaxation, and by how much, in the order of the constraints.
+func (r *Relaxation) Violations() []Violation {
+	var violations []Violation
+	for i, sc := range r.soft {
+		if amount := sc.Violation(); amount > 0 {
+			violations = append(violations, Violation{r.original.constraints[i], amount})
+		}
+	}
+	return violations
+}
@@ -0,0 +1,76 @@
+package mip
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSoftConstraint(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 10)
+	y := m.VarInt("y", 0, 10)
+	c, _ := m.AddNamedConstraint("cap", sumOf(x, y), LessThanOrEqual, 6)
+	demand := m.AddSoftConstraint(sumOf(x), GreaterThanOrEqual, 8, 3)
+	target := m.AddSoftConstraint(sumOf(y), Equal, 2, 1)
+	objective := sumOf(y)
+	m.SetObjective(objective, Maximize)
+
+	s, _ := newTestSolver(m)
+	result, err := s.Solve(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// x = 6 and y = 0 violate demand by 2 and target by 2: 0 - 3*2 - 1*2 = -8 beats x = 4, y = 2: 2 - 3*4 = -10
+	if result.ObjectiveValue != -8 || x.Value() != 6 || demand.Violation() != 2 || target.Violation() != 2 || c.Slack() != 0 {
+		t.Errorf("got objective %v with x = %v, violations %v and %v, want -8, 6, 2 and 2",
+			result.ObjectiveValue, x.Value(), demand.Violation(), target.Violation())
+	}
+	if demand.Penalty() != 3 || m.VariableByName("x_under") != nil || m.NumVariables() != 5 {
+		t.Errorf("got penalty %v and %d variables, want 3 and 5 with anonymous slacks", demand.Penalty(), m.NumVariables())
+	}
+	if objective, _ := m.Objective(); objective.String() != "y" {
+		t.Errorf("got the objective %s, want the penalties left out", objective)
+	}
+
+	m.SetObjective(NewLinearExpression(), Minimize)
+	if result, err := s.Solve(0); err != nil || result.ObjectiveValue != 8 || demand.Violation() != 2 {
+		t.Errorf("got %+v, %v, want the penalties minimized to 8", result, err)
+	}
+}
+
+func TestSoftConstraintPanicsOnInvalidPenalty(t *testing.T) {
+	for _, penalty := range []float64{-1, math.Inf(1), math.NaN()} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("no panic for the penalty %v", penalty)
+				}
+			}()
+			m := NewModel()
+			m.AddSoftConstraint(sumOf(m.VarBool("x")), LessThanOrEqual, 0, penalty)
+		}()
+	}
+}
+
+func TestFeasibilityRelaxation(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 5)
+	y := m.VarInt("y", 0, 5)
+	demand, _ := m.AddNamedConstraint("demand", sumOf(x, y), GreaterThanOrEqual, 12)
+	m.AddNamedConstraint("cap", sumOf(x), LessThanOrEqual, 3)
+	m.SetObjective(sumOf(x), Maximize)
+
+	r := m.FeasibilityRelaxation()
+	s, _ := newTestSolver(r.Model)
+	result, err := s.Solve(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	violations := r.Violations()
+	if result.ObjectiveValue != 4 || len(violations) != 1 || violations[0].Constraint != demand || violations[0].Amount != 4 {
+		t.Errorf("got total violation %v and %+v, want demand violated by 4", result.ObjectiveValue, violations)
+	}
+	if r.VariableByName("demand_under") == nil || m.NumVariables() != 2 || demand.Expression().String() != "x + y" {
+		t.Error("the original Model was changed")
+	}
+}
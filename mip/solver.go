@@ -1,65 +1,512 @@
 package mip
 
-import "C"
 import (
+	"context"
 	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 )
 
-// Solver represents the optimization problem to be solved.
-// This is an export alias
-type Solver = solver
+// Solver solves a Model with a Backend, by default one of the OR-Tools solvers.
+// The Model is embedded, so variables, constraints and the objective can be added to the Solver directly.
+//
+// The methods of a Solver can be called from several goroutines, they are serialized by a lock guarding the Backend:
+// e.g. ReleaseResources waits for a running Solve. Like a map, the Model must not be modified concurrently, and
+// modifying it or solving it with another Solver while it is being solved panics. Use a Pool for parallel solves.
+type Solver struct {
+	*Model
+	mu       sync.Mutex // guards the fields below, held during Solve
+	backend  Backend    // nil once released
+	released bool
 
+	// state of the variables, constraints and objective variables of the Model already passed to the backend
+	loadedVars       []loadedVariable
+	loadedCons       []loadedConstraint
+	loadedIndicators int
+	loadedSOS        int
+	loadedObjective  []*Variable
+	reformulations   []*reformulation
+	numColumns       int // variables of the backend, including reformulations
+	numRows          int // constraints of the backend, including reformulations
+
+	params     SolverParams
+	hint       map[*Variable]float64
+	lastResult SolveResult
+}
+
+// Solver types of the OR-Tools MPSolver backends. Which ones can be used depends on how the OR-Tools library
+// was built, see AvailableSolverTypes. Commercial solvers also need their own library and license at runtime.
 const (
-	SCIP = "SCIP"
-	CBC  = "CBC"
+	SCIP   = "SCIP"
+	CBC    = "CBC"
+	GLOP   = "GLOP" // linear programming only, provides dual values
+	CLP    = "CLP"  // linear programming only, provides dual values
+	PDLP   = "PDLP" // first-order linear programming for very large models, provides dual values
+	CPSAT  = "CP_SAT"
+	BOP    = "BOP" // binary variables only
+	GLPK   = "GLPK"
+	HIGHS  = "HIGHS"
+	GUROBI = "GUROBI"
+	XPRESS = "XPRESS"
+	CPLEX  = "CPLEX"
 )
 
-// NewSolver creates and returns a new Solver of the given type.
+// solverTypes lists the solver type constants, in the order of AvailableSolverTypes.
+var solverTypes = []string{SCIP, CBC, GLOP, CLP, PDLP, CPSAT, BOP, GLPK, HIGHS, GUROBI, XPRESS, CPLEX}
+
+// AvailableSolverTypes returns the solver types that NewSolver can create,
+// i.e. those linked into the OR-Tools library loaded at runtime. It is empty without cgo.
+func AvailableSolverTypes() []string {
+	var available []string
+	for _, solverType := range solverTypes {
+		if isSolverTypeAvailable(solverType) {
+			available = append(available, solverType)
+		}
+	}
+	return available
+}
+
+// loadedVariable is the state of a variable when it was last passed to the backend.
+type loadedVariable struct {
+	lowerBound, upperBound float64
+	integer                bool
+	column                 int // index of the variable in the backend
+}
+
+// loadedConstraint is the state of a constraint when it was last passed to the backend.
+type loadedConstraint struct {
+	lowerBound, upperBound float64
+	row                    int // index of the constraint in the backend
+}
+
+// reformulation is a constraint passed to a backend without native support for it as rows derived from the bounds
+// of its variables, e.g. the big-M rows of an indicator constraint or the rows bounding the variables of a special
+// ordered set by binary variables.
+type reformulation struct {
+	vars         []*Variable
+	lower, upper []float64 // bounds of the variables when the rows were built
+	rows         []int     // rows of the backend
+	build        func() ([]int, error)
+}
+
+// update builds the rows of the reformulation the first time, and rebuilds them when the bounds of its variables
+// were widened since, as the rows may then cut off solutions. The previous rows are relaxed, and kept if the
+// rows cannot be rebuilt. Rows built with wider bounds remain valid, so narrowed bounds keep them.
+func (r *reformulation) update(s *Solver) error {
+	widened := r.lower == nil
+	for i, v := range r.vars {
+		widened = widened || v.lowerBound < r.lower[i] || v.upperBound > r.upper[i]
+	}
+	if !widened {
+		return nil
+	}
+
+	rows, err := r.build()
+	if err != nil {
+		return err
+	}
+	for _, row := range r.rows {
+		s.backend.SetConstraintBounds(row, math.Inf(-1), math.Inf(1))
+	}
+	r.rows = rows
+	r.lower, r.upper = make([]float64, len(r.vars)), make([]float64, len(r.vars))
+	for i, v := range r.vars {
+		r.lower[i], r.upper[i] = v.lowerBound, v.upperBound
+	}
+	return nil
+}
+
+// NewSolver creates and returns a new Solver of the given type, with an empty Model.
 func NewSolver(solverType string) (*Solver, error) {
+	return NewSolverForModel(NewModel(), solverType)
+}
 
-	if solverType != SCIP && solverType != CBC {
-		return nil, fmt.Errorf("unsupported solver type")
+// NewSolverForModel creates and returns a new Solver of the given type for an existing Model.
+// It returns an error listing the available solver types if the solver is not available in the OR-Tools build.
+func NewSolverForModel(m *Model, solverType string) (*Solver, error) {
+	if err := checkSolverType(solverType); err != nil {
+		return nil, err
 	}
 
-	solver := createSolver(solverType)
-	if solver == nil {
-		return nil, fmt.Errorf("failed to create Solver")
+	b, err := newDefaultBackend(solverType)
+	if err != nil {
+		return nil, err
 	}
+	return &Solver{Model: m, backend: b}, nil
+}
 
-	return solver, nil
+// checkSolverType returns an error if the solver type is unknown or not available.
+func checkSolverType(solverType string) error {
+	if !slices.Contains(solverTypes, solverType) {
+		return fmt.Errorf("unsupported solver type %q, supported types are %s", solverType, strings.Join(solverTypes, ", "))
+	}
+	if !isSolverTypeAvailable(solverType) {
+		available := "none"
+		if types := AvailableSolverTypes(); len(types) > 0 {
+			available = strings.Join(types, ", ")
+		}
+		return fmt.Errorf("solver type %s is not available in this OR-Tools build, available types are: %s", solverType, available)
+	}
+	return nil
 }
 
-// ReleaseResources frees up the memory in the C heap allocated for the Solver.
+// NewSolverWithBackend creates and returns a new Solver solving the given Model with the given Backend.
+// The Backend must be empty, the Solver takes ownership of it.
+func NewSolverWithBackend(m *Model, b Backend) *Solver {
+	return &Solver{Model: m, backend: b}
+}
+
+// ReleaseResources frees up the resources held by the Backend, e.g. the memory in the C heap for OR-Tools solvers.
+// The Model and the values of the last solution remain available, but solving again returns ErrReleased.
+// It can be called several times. OR-Tools solvers that are never released are freed when garbage collected,
+// see SetLeakReporter.
 func (s *Solver) ReleaseResources() {
-	s.delete()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.backend != nil {
+		s.backend.Release()
+		s.backend = nil
+	}
+	s.released = true
+}
+
+// materialise passes to the backend the part of the Model it does not know about yet.
+func (s *Solver) materialise() error {
+	if s.released {
+		return ErrReleased
+	}
+
+	for i, loaded := range s.loadedVars {
+		v := s.variables[i]
+		if v.lowerBound != loaded.lowerBound || v.upperBound != loaded.upperBound {
+			s.backend.SetVariableBounds(loaded.column, v.lowerBound, v.upperBound)
+		}
+		if v.integer != loaded.integer {
+			s.backend.SetVariableInteger(loaded.column, v.integer)
+		}
+		s.loadedVars[i] = loadedVariable{v.lowerBound, v.upperBound, v.integer, loaded.column}
+	}
+
+	for _, v := range s.variables[len(s.loadedVars):] {
+		column := s.addColumn(v.name, v.lowerBound, v.upperBound, v.integer)
+		s.loadedVars = append(s.loadedVars, loadedVariable{v.lowerBound, v.upperBound, v.integer, column})
+	}
+
+	for i, loaded := range s.loadedCons {
+		c := s.constraints[i]
+		if c.lowerBound != loaded.lowerBound || c.upperBound != loaded.upperBound {
+			s.backend.SetConstraintBounds(loaded.row, c.lowerBound, c.upperBound)
+		}
+		s.loadedCons[i] = loadedConstraint{c.lowerBound, c.upperBound, loaded.row}
+	}
+
+	for _, c := range s.constraints[len(s.loadedCons):] {
+		row := s.addRow(c)
+		s.loadedCons = append(s.loadedCons, loadedConstraint{c.lowerBound, c.upperBound, row})
+	}
+
+	for _, r := range s.reformulations {
+		if err := r.update(s); err != nil {
+			return err
+		}
+	}
+
+	indicatorAdder, _ := s.backend.(IndicatorAdder)
+	for _, ic := range s.indicators[s.loadedIndicators:] {
+		vars, coeffs := s.terms(ic.expr)
+		if indicatorAdder == nil || !indicatorAdder.AddIndicatorConstraint(s.column(ic.indicator), ic.active, ic.lowerBound, ic.upperBound, vars, coeffs) {
+			// the big-M rows are only valid for a binary indicator variable
+			r := &reformulation{vars: append(ic.expr.Vars(), ic.indicator), build: func() ([]int, error) { return s.addBigMRows(ic) }}
+			if err := r.update(s); err != nil {
+				return err
+			}
+			s.reformulations = append(s.reformulations, r)
+		}
+		s.loadedIndicators++
+	}
+
+	sosAdder, _ := s.backend.(SOSAdder)
+	for _, sos := range s.sos[s.loadedSOS:] {
+		vars := make([]int, len(sos.vars))
+		for i, v := range sos.vars {
+			vars[i] = s.column(v)
+		}
+		if sosAdder == nil || !sosAdder.AddSOS(sos.sosType, vars, slices.Clone(sos.weights)) {
+			r := &reformulation{vars: sos.vars, build: func() ([]int, error) { return s.addSOSBinaries(sos) }}
+			if err := r.update(s); err != nil {
+				return err
+			}
+			s.reformulations = append(s.reformulations, r)
+		}
+		s.loadedSOS++
+	}
+
+	objective := s.fullObjective()
+	for _, v := range s.loadedObjective {
+		if _, ok := objective.terms[v]; !ok {
+			s.backend.SetObjectiveCoefficient(s.column(v), 0) // left over from a replaced objective
+		}
+	}
+	s.loadedObjective = objective.Vars()
+	for _, v := range s.loadedObjective {
+		s.backend.SetObjectiveCoefficient(s.column(v), objective.terms[v])
+	}
+	s.backend.SetObjectiveOffset(objective.constant)
+	s.backend.SetOptimizationType(s.sense)
+	if err := s.backendErr(); err != nil {
+		return fmt.Errorf("loading the model into the solver: %w", err)
+	}
+
+	if paramSetter, ok := s.backend.(ParamSetter); ok {
+		return paramSetter.SetParams(s.params)
+	}
+	if s.params != (SolverParams{}) {
+		return fmt.Errorf("the backend does not support solver parameters")
+	}
+	return nil
+}
+
+// column returns the index in the backend of a variable already passed to it.
+func (s *Solver) column(v *Variable) int { return s.loadedVars[v.index].column }
+
+// terms returns the backend indices of the variables of the expression, in index order, and their coefficients.
+func (s *Solver) terms(e *LinearExpression) ([]int, []float64) {
+	vars := make([]int, 0, len(e.terms))
+	coeffs := make([]float64, 0, len(e.terms))
+	for _, v := range e.Vars() {
+		vars = append(vars, s.column(v))
+		coeffs = append(coeffs, e.terms[v])
+	}
+	return vars, coeffs
+}
+
+// addColumn adds a variable to the backend and returns its index in the backend.
+func (s *Solver) addColumn(name string, lb, ub float64, integer bool) int {
+	s.backend.AddVariable(name, lb, ub, integer)
+	s.numColumns++
+	return s.numColumns - 1
+}
+
+// addRow adds a constraint to the backend and returns its index in the backend.
+func (s *Solver) addRow(c *Constraint) int {
+	vars, coeffs := s.terms(c.expr)
+	return s.addBackendRow(c.name, c.lowerBound, c.upperBound, vars, coeffs)
+}
+
+// addBackendRow adds a row over backend variables and returns its index in the backend.
+func (s *Solver) addBackendRow(name string, lb, ub float64, vars []int, coeffs []float64) int {
+	s.backend.AddConstraint(name, lb, ub, vars, coeffs)
+	s.numRows++
+	return s.numRows - 1
 }
 
 // Solve attempts to solve the optimization problem within the given time limit.
-// It returns a SolveResult containing the solution status, objective value, best bound, and gap.
-func (s *Solver) Solve(timeLimit time.Duration) (isOptimal bool, err error) {
-	if timeLimit > 0 {
-		s.setTimeLimit(timeLimit.Milliseconds())
-	}
-
-	status := ResultStatus(s.solve())
-
-	switch status {
-	case Optimal:
-		return true, nil
-	case Feasible:
-		return false, nil
-	case Infeasible:
-		return false, fmt.Errorf("the problem is infeasible")
-	case Unbounded:
-		return false, fmt.Errorf("the problem is unbounded")
-	case Abnormal:
-		return false, fmt.Errorf("the solver encountered an abnormal situation")
-	case ModelInvalid:
-		return false, fmt.Errorf("the model is invalid")
-	case NotSolved:
-		return false, fmt.Errorf("the problem was not solved")
+// A non-positive time limit means no limit.
+// It returns a SolveResult containing the solution status, objective value, best bound, gap and statistics.
+// If no solution was found, the error is one of ErrInfeasible, ErrUnbounded, ErrAbnormal, ErrModelInvalid, ErrNotSolved.
+func (s *Solver) Solve(timeLimit time.Duration) (SolveResult, error) {
+	return s.solve(context.Background(), timeLimit)
+}
+
+// SolveContext is like Solve, but the time limit is given by the deadline of the context, if any,
+// and the solve is interrupted when the context is cancelled.
+// An interrupted solve returns the best solution found so far, if any, with the Cancelled status
+// and an error matching both ErrCancelled and the context error.
+// A solve that completes anyway, proving optimality, infeasibility or unboundedness, keeps its status.
+// Backends that do not implement Interrupter, or whose solver cannot be interrupted (e.g. CBC),
+// only stop at the deadline.
+func (s *Solver) SolveContext(ctx context.Context) (SolveResult, error) {
+	if err := ctx.Err(); err != nil {
+		return SolveResult{Status: Cancelled}, fmt.Errorf("%w: %w", ErrCancelled, err)
+	}
+
+	return s.solve(ctx, contextTimeLimit(ctx))
+}
+
+// contextTimeLimit returns the time left until the deadline of the context, 0 (no limit) if it has none.
+func contextTimeLimit(ctx context.Context) time.Duration {
+	if deadline, ok := ctx.Deadline(); ok {
+		return max(time.Until(deadline), time.Millisecond)
+	}
+	return 0
+}
+
+func (s *Solver) solve(ctx context.Context, timeLimit time.Duration) (SolveResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.beginSolve()
+	defer s.endSolve()
+
+	if err := s.Err(); err != nil {
+		return SolveResult{Status: NotSolved}, err
+	}
+	if err := s.materialise(); err != nil {
+		return SolveResult{Status: NotSolved}, err
+	}
+
+	hintAccepted := s.applyHint()
+
+	s.backend.SetTimeLimit(timeLimit)
+	start := time.Now()
+	status, interrupted := s.runBackend(ctx)
+	if err := s.backendErr(); err != nil {
+		s.lastResult = SolveResult{Status: Abnormal, HintAccepted: hintAccepted}
+		return s.lastResult, fmt.Errorf("%w: %w", ErrAbnormal, err)
+	}
+
+	result := SolveResult{Status: status, HintAccepted: hintAccepted, solutionFound: status == Optimal || status == Feasible}
+	result.WallTime = time.Since(start)
+	result.Iterations = s.backend.Iterations()
+	result.Nodes = s.backend.Nodes()
+
+	if result.solutionFound {
+		s.readVariableSolution()
+		s.readConstraintSolution()
+		result.ObjectiveValue = s.backend.ObjectiveValue()
+		result.BestBound = s.backend.BestBound()
+		result.Gap = relativeGap(result.ObjectiveValue, result.BestBound)
+	}
+
+	err := result.err()
+	if interrupted {
+		result.Status = Cancelled
+		err = fmt.Errorf("%w: %w", ErrCancelled, ctx.Err())
+	}
+
+	s.lastResult = result
+	return result, err
+}
+
+// backendErr returns the first error reported by the backend, if it reports errors.
+func (s *Solver) backendErr() error {
+	if reporter, ok := s.backend.(ErrorReporter); ok {
+		return reporter.Err()
+	}
+	return nil
+}
+
+// readVariableSolution fills the values, and the dual information when available, of the variables.
+func (s *Solver) readVariableSolution() {
+	dualReader, hasDuals := s.backend.(DualReader)
+	hasDuals = hasDuals && dualReader.HasDuals()
+
+	for _, v := range s.variables {
+		column := s.column(v)
+		v.value = s.backend.Value(column)
+		v.reducedCost, v.basisStatus = 0, BasisFree
+		if hasDuals {
+			v.reducedCost = dualReader.ReducedCost(column)
+			v.basisStatus = dualReader.VariableBasisStatus(column)
+		}
+	}
+}
+
+// readConstraintSolution fills the activities, and the dual information when available, of the constraints.
+func (s *Solver) readConstraintSolution() {
+	dualReader, hasDuals := s.backend.(DualReader)
+	hasDuals = hasDuals && dualReader.HasDuals()
+
+	for _, c := range s.constraints {
+		c.activity = c.expr.solutionValue()
+		c.dualValue, c.basisStatus = 0, BasisFree
+		if hasDuals {
+			row := s.loadedCons[c.index].row
+			c.dualValue = dualReader.DualValue(row)
+			c.basisStatus = dualReader.ConstraintBasisStatus(row)
+		}
+	}
+	for _, ic := range s.indicators {
+		ic.activity = ic.expr.solutionValue()
+	}
+}
+
+// SetHint sets a solution hint (warm start) used by the next solves, replacing any previous one.
+// A nil or empty hint clears it. Variables missing from the hint are left to the solver.
+// Solvers supporting hints (e.g. SCIP) may start from a complete feasible hint as their first incumbent,
+// SolveResult.HintAccepted reports whether the hint was such a hint. Other solvers ignore hints.
+func (s *Solver) SetHint(hint map[*Variable]float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hint = make(map[*Variable]float64, len(hint))
+	for v, value := range hint {
+		if v.model != s.Model {
+			panic("variable " + v.name + " does not belong to this model")
+		}
+		s.hint[v] = value
+	}
+}
+
+// applyHint passes the hint to the backend and reports whether it was a complete feasible solution passed to
+// a solver making use of hints. The backend does not tell whether the solver actually starts from it.
+func (s *Solver) applyHint() bool {
+	hinter, ok := s.backend.(Hinter)
+	if !ok {
+		return false
+	}
+
+	vars := make([]int, 0, len(s.hint))
+	values := make([]float64, 0, len(s.hint))
+	for _, v := range s.variables {
+		if value, ok := s.hint[v]; ok {
+			vars = append(vars, s.column(v))
+			values = append(values, value)
+		}
+	}
+	used := hinter.SetHint(vars, values)
+
+	return used && len(s.hint) > 0 && s.CheckSolution(s.hint) == nil
+}
+
+// runBackend runs the backend Solve, interrupting it if the context is done first.
+// It reports whether the solve was interrupted: a solve that finished as the context was done, or that reached
+// a conclusive status despite the interruption, keeps its status.
+func (s *Solver) runBackend(ctx context.Context) (status ResultStatus, interrupted bool) {
+	done := make(chan ResultStatus, 1)
+	go func() { done <- s.backend.Solve() }()
+
+	select {
+	case status = <-done:
+		return status, false
+	case <-ctx.Done():
+	}
+	select {
+	case status = <-done:
+		return status, false
 	default:
-		return false, fmt.Errorf("unknown result status")
 	}
+
+	if interrupter, ok := s.backend.(Interrupter); ok {
+		interrupter.Interrupt()
+	}
+	status = <-done
+	return status, status != Optimal && status != Infeasible && status != Unbounded
+}
+
+// ObjectiveValue returns the best objective value found by the last Solve.
+func (s *Solver) ObjectiveValue() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastResult.ObjectiveValue
+}
+
+// BestBound returns what's currently the best bound.
+// for example, if the problem is being minimized, and the best bound is 100,
+// then the theoretical optimal objective is at least 100.
+// This can be used to evaluate the quality of the solution.
+func (s *Solver) BestBound() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastResult.BestBound
+}
+
+// Gap returns the relative gap between the best integer solution found and the best bound.
+// A gap of 0.5 means that the best solution is at most 50% away from the best bound.
+// The gap is relative to the objective value: it is +Inf if the objective value is 0 and the best bound is not.
+func (s *Solver) Gap() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastResult.Gap
 }
@@ -0,0 +1,474 @@
+package mip
+
+import (
+	"context"
+	"errors"
+	"math"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// testRange bounds the values enumerated by testBackend.
+const testRange = 20
+
+// testBackend is a Backend solving small models by enumerating the integer points within the bounds of the variables,
+// clamped to [-testRange, testRange]: continuous variables only take integer values. It records what it is given.
+type testBackend struct {
+	columns   []testColumn
+	rows      []testRow
+	offset    float64
+	sense     OptimizationType
+	timeLimit time.Duration
+
+	solves       int
+	boundUpdates int // calls to SetVariableBounds and SetConstraintBounds
+	values       []float64
+	objective    float64
+	released     bool
+
+	// onSolve, if set, is called at the end of Solve with the status found and returns the status to report.
+	onSolve func(ResultStatus) ResultStatus
+	// interrupted, if set, is closed by Interrupt.
+	interrupted chan struct{}
+	// params are the parameters last set, paramsErr is returned by SetParams.
+	params    SolverParams
+	paramsErr error
+	// hints makes SetHint report that hints are used, hintVars and hintValues are the hint last set.
+	hints      bool
+	hintVars   []int
+	hintValues []float64
+	// err is returned by Err.
+	err error
+	// duals makes the backend report made-up dual information: the dual value of row i is i + 0.5,
+	// the reduced cost of column j is -j, and every row and column is basic.
+	duals bool
+	// indicators makes AddIndicatorConstraint report native support, indicatorRows are the indicator constraints
+	// recorded, without being enforced, with the indicator variable last in vars.
+	indicators    bool
+	indicatorRows []testRow
+	// sets makes AddSOS report native support, setRows are the special ordered sets recorded, without being enforced,
+	// with the weights as coefficients.
+	sets    bool
+	setRows []testRow
+}
+
+type testColumn struct {
+	name    string
+	lb, ub  float64
+	integer bool
+	coeff   float64 // in the objective
+}
+
+type testRow struct {
+	name   string
+	lb, ub float64
+	vars   []int
+	coeffs []float64
+}
+
+func (b *testBackend) AddVariable(name string, lb, ub float64, integer bool) {
+	b.columns = append(b.columns, testColumn{name: name, lb: lb, ub: ub, integer: integer})
+}
+
+func (b *testBackend) AddConstraint(name string, lb, ub float64, vars []int, coeffs []float64) {
+	b.rows = append(b.rows, testRow{name, lb, ub, vars, coeffs})
+}
+
+func (b *testBackend) SetConstraintBounds(constraint int, lb, ub float64) {
+	b.rows[constraint].lb, b.rows[constraint].ub = lb, ub
+	b.boundUpdates++
+}
+
+func (b *testBackend) SetVariableBounds(variable int, lb, ub float64) {
+	b.columns[variable].lb, b.columns[variable].ub = lb, ub
+	b.boundUpdates++
+}
+
+func (b *testBackend) SetVariableInteger(variable int, integer bool) {
+	b.columns[variable].integer = integer
+}
+
+func (b *testBackend) SetObjectiveCoefficient(variable int, coeff float64) {
+	b.columns[variable].coeff = coeff
+}
+
+func (b *testBackend) SetObjectiveOffset(offset float64) { b.offset = offset }
+
+func (b *testBackend) SetOptimizationType(tp OptimizationType) { b.sense = tp }
+
+func (b *testBackend) SetTimeLimit(timeLimit time.Duration) { b.timeLimit = timeLimit }
+
+func (b *testBackend) Solve() ResultStatus {
+	status := b.enumerate()
+	if b.onSolve != nil {
+		status = b.onSolve(status)
+	}
+	return status
+}
+
+// enumerate finds the best integer point satisfying the rows, if any.
+func (b *testBackend) enumerate() ResultStatus {
+	b.solves++
+	b.values = nil
+
+	// a row is checked once its last variable is assigned
+	rowsAt := make([][]testRow, len(b.columns))
+	for _, row := range b.rows {
+		last := -1
+		for _, v := range row.vars {
+			last = max(last, v)
+		}
+		if last < 0 {
+			if row.lb > 1e-9 || row.ub < -1e-9 {
+				return Infeasible
+			}
+			continue
+		}
+		rowsAt[last] = append(rowsAt[last], row)
+	}
+
+	x := make([]float64, len(b.columns))
+	best := math.Inf(1)
+	var enumerate func(i int)
+	enumerate = func(i int) {
+		if i == len(x) {
+			objective := b.offset
+			for j, c := range b.columns {
+				objective += c.coeff * x[j]
+			}
+			if b.sense == Maximize {
+				objective = -objective
+			}
+			if objective < best {
+				best = objective
+				b.values = append(b.values[:0], x...)
+			}
+			return
+		}
+		c := b.columns[i]
+		for value := math.Ceil(math.Max(c.lb, -testRange)); value <= math.Min(c.ub, testRange); value++ {
+			x[i] = value
+			feasible := true
+			for _, row := range rowsAt[i] {
+				activity := 0.
+				for k, v := range row.vars {
+					activity += row.coeffs[k] * x[v]
+				}
+				if activity < row.lb-1e-9 || activity > row.ub+1e-9 {
+					feasible = false
+					break
+				}
+			}
+			if feasible {
+				enumerate(i + 1)
+			}
+		}
+	}
+	enumerate(0)
+
+	if b.values == nil {
+		return Infeasible
+	}
+	b.objective = best
+	if b.sense == Maximize {
+		b.objective = -best
+	}
+	return Optimal
+}
+
+func (b *testBackend) ObjectiveValue() float64 { return b.objective }
+func (b *testBackend) BestBound() float64      { return b.objective }
+func (b *testBackend) Iterations() int64       { return 0 }
+func (b *testBackend) Nodes() int64            { return -1 }
+func (b *testBackend) Value(variable int) float64 {
+	return b.values[variable]
+}
+func (b *testBackend) Release() { b.released = true }
+
+func (b *testBackend) SetParams(p SolverParams) error {
+	b.params = p
+	return b.paramsErr
+}
+
+func (b *testBackend) SetHint(vars []int, values []float64) bool {
+	b.hintVars, b.hintValues = vars, values
+	return b.hints
+}
+
+func (b *testBackend) HasDuals() bool                        { return b.duals }
+func (b *testBackend) DualValue(constraint int) float64      { return float64(constraint) + 0.5 }
+func (b *testBackend) ConstraintBasisStatus(int) BasisStatus { return Basic }
+func (b *testBackend) ReducedCost(variable int) float64      { return -float64(variable) }
+func (b *testBackend) VariableBasisStatus(int) BasisStatus   { return Basic }
+
+func (b *testBackend) Err() error { return b.err }
+
+func (b *testBackend) AddIndicatorConstraint(indicator int, active bool, lb, ub float64, vars []int, coeffs []float64) bool {
+	if b.indicators {
+		b.indicatorRows = append(b.indicatorRows, testRow{name: "indicator", lb: lb, ub: ub, vars: append(vars, indicator), coeffs: coeffs})
+	}
+	return b.indicators
+}
+
+func (b *testBackend) AddSOS(sosType SOSType, vars []int, weights []float64) bool {
+	if b.sets {
+		b.setRows = append(b.setRows, testRow{name: "SOS", vars: vars, coeffs: weights})
+	}
+	return b.sets
+}
+
+func (b *testBackend) Interrupt() bool {
+	if b.interrupted == nil {
+		return false
+	}
+	close(b.interrupted)
+	return true
+}
+
+// newTestSolver returns a Solver of the Model with a testBackend.
+func newTestSolver(m *Model) (*Solver, *testBackend) {
+	b := &testBackend{}
+	return NewSolverWithBackend(m, b), b
+}
+
+func TestSolveReadsSolution(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 10)
+	y := m.VarInt("y", 0, 10)
+	z := m.VarBool("z")
+	e := NewLinearExpression()
+	e.AddTerm(x, 2)
+	e.AddTerm(y, 3)
+	m.AddConstraintExpr(e, LessThanOrEqual, 12)
+	objective := NewLinearExpression()
+	objective.AddTerm(x, 3)
+	objective.AddTerm(y, 4)
+	objective.AddTerm(z, 1)
+	m.SetObjective(objective, Maximize)
+
+	s, b := newTestSolver(m)
+	result, err := s.Solve(time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsOptimal() || !result.HasSolution() || result.ObjectiveValue != 19 || result.Gap != 0 || result.Nodes != -1 {
+		t.Errorf("got %+v, want an optimal solution of value 19", result)
+	}
+	if s.ObjectiveValue() != 19 || s.BestBound() != 19 || s.Gap() != 0 {
+		t.Errorf("got objective %v, bound %v and gap %v, want 19, 19 and 0", s.ObjectiveValue(), s.BestBound(), s.Gap())
+	}
+	if x.Value() != 6 || y.Value() != 0 || z.Value() != 1 {
+		t.Errorf("got x = %v, y = %v, z = %v, want 6, 0, 1", x.Value(), y.Value(), z.Value())
+	}
+	if len(b.columns) != 3 || len(b.rows) != 1 || b.columns[0].name != "x" || b.sense != Maximize || b.timeLimit != time.Second {
+		t.Errorf("unexpected backend state %+v", b)
+	}
+}
+
+func TestSolveMaterialisesIncrementally(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 10)
+	e := NewLinearExpression()
+	e.AddVar(x)
+	m.AddConstraintExpr(e, LessThanOrEqual, 8)
+	m.SetObjective(e, Maximize)
+
+	s, b := newTestSolver(m)
+	if _, err := s.Solve(0); err != nil {
+		t.Fatal(err)
+	}
+
+	y := m.VarInt("y", 0, 3)
+	e.AddTerm(y, -1)
+	m.AddConstraintExpr(e, LessThanOrEqual, 4)
+	x.SetBounds(0, 6)
+	if _, err := s.Solve(0); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(b.columns) != 2 || len(b.rows) != 2 || b.solves != 2 {
+		t.Errorf("got %d columns and %d rows after %d solves, want 2, 2 and 2", len(b.columns), len(b.rows), b.solves)
+	}
+	if b.boundUpdates != 1 || b.columns[0].ub != 6 {
+		t.Errorf("got %d bound updates and x <= %v, want 1 and 6", b.boundUpdates, b.columns[0].ub)
+	}
+	if x.Value() != 6 || s.ObjectiveValue() != 6 {
+		t.Errorf("got x = %v, objective %v, want 6 and 6", x.Value(), s.ObjectiveValue())
+	}
+}
+
+func TestReleaseResources(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 3)
+	e := NewLinearExpression()
+	e.AddVar(x)
+	m.SetObjective(e, Maximize)
+	s, b := newTestSolver(m)
+	if _, err := s.Solve(0); err != nil {
+		t.Fatal(err)
+	}
+	s.ReleaseResources()
+	s.ReleaseResources()
+	if !b.released {
+		t.Error("the backend was not released")
+	}
+	if x.Value() != 3 || s.ObjectiveValue() != 3 {
+		t.Errorf("got x = %v and objective %v after release, want the last solution", x.Value(), s.ObjectiveValue())
+	}
+	if _, err := s.Solve(0); !errors.Is(err, ErrReleased) || b.solves != 1 {
+		t.Errorf("got %v after %d solves, want ErrReleased without solving", err, b.solves)
+	}
+}
+
+func TestSolveContextInterrupts(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 3)
+	s, b := newTestSolver(m)
+	started := make(chan struct{})
+	b.interrupted = make(chan struct{})
+	b.onSolve = func(ResultStatus) ResultStatus {
+		close(started)
+		<-b.interrupted
+		return Feasible
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	result, err := s.SolveContext(ctx)
+	if !errors.Is(err, ErrCancelled) || !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want ErrCancelled and context.Canceled", err)
+	}
+	if result.Status != Cancelled || !result.HasSolution() || x.Value() != 0 {
+		t.Errorf("got %+v, want the solution found before the interruption", result)
+	}
+
+	if _, err := s.SolveContext(ctx); !errors.Is(err, ErrCancelled) || b.solves != 1 {
+		t.Errorf("got %v after %d solves, want ErrCancelled without solving", err, b.solves)
+	}
+}
+
+func TestSolveContextDeadline(t *testing.T) {
+	m := NewModel()
+	m.VarInt("x", 0, 3)
+	s, b := newTestSolver(m)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if _, err := s.SolveContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if b.timeLimit <= 59*time.Second || b.timeLimit > time.Minute {
+		t.Errorf("got a time limit of %v, want the time until the deadline", b.timeLimit)
+	}
+}
+
+func TestSolveContextKeepsFinishedStatus(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 3)
+	m.SetObjective(sumOf(x), Maximize)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s, b := newTestSolver(m)
+	b.interrupted = make(chan struct{})
+	b.onSolve = func(status ResultStatus) ResultStatus {
+		cancel()
+		return status
+	}
+	result, err := s.SolveContext(ctx)
+	if err != nil || result.Status != Optimal || x.Value() != 3 {
+		t.Errorf("got %+v, %v, want the optimal solution", result, err)
+	}
+}
+
+func TestSolveReplacesObjective(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 3)
+	y := m.VarInt("y", 0, 3)
+	m.SetObjective(sumOf(x), Maximize)
+
+	s, b := newTestSolver(m)
+	if _, err := s.Solve(0); err != nil {
+		t.Fatal(err)
+	}
+	second := sumOf(y)
+	second.AddConstant(10)
+	m.SetObjective(second, Maximize)
+	result, err := s.Solve(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.columns[0].coeff != 0 || b.columns[1].coeff != 1 || b.offset != 10 || result.ObjectiveValue != 13 {
+		t.Errorf("got coefficients %v and %v, offset %v, objective %v, want 0, 1, 10 and 13",
+			b.columns[0].coeff, b.columns[1].coeff, b.offset, result.ObjectiveValue)
+	}
+}
+
+func TestModelObjective(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 3)
+	if objective, sense := m.Objective(); len(objective.Vars()) != 0 || objective.Constant() != 0 || sense != Minimize {
+		t.Errorf("got %v %v, want an empty objective to minimize", sense, objective.terms)
+	}
+	e := sumOf(x)
+	e.AddConstant(2)
+	m.SetObjective(e, Maximize)
+	e.AddVar(x)
+
+	objective, sense := m.Objective()
+	if objective.Coefficient(x) != 1 || objective.Constant() != 2 || sense != Maximize {
+		t.Errorf("got %v %v + %v, want x + 2 to maximize", sense, objective.terms, objective.Constant())
+	}
+	objective.AddVar(x)
+	if again, _ := m.Objective(); again.Coefficient(x) != 1 {
+		t.Error("changing the returned objective changed the Model")
+	}
+}
+
+func TestLeakReporter(t *testing.T) {
+	var leaks []string
+	SetLeakReporter(func(leak string) { leaks = append(leaks, leak) })
+	reportLeak("solver 1")
+	SetLeakReporter(nil)
+	reportLeak("solver 2")
+	if len(leaks) != 1 || leaks[0] != "solver 1" {
+		t.Errorf("got %v, want the leak reported before disabling the reports", leaks)
+	}
+}
+
+func TestLeakFinalizer(t *testing.T) {
+	leaks := make(chan string, 2)
+	SetLeakReporter(func(leak string) { leaks <- leak })
+	defer SetLeakReporter(nil)
+
+	// handle stands for the C solver of cgo_wrapper.go, large enough not to be batched by the tiny allocator
+	type handle struct {
+		name  string
+		freed chan string
+	}
+	freed := make(chan string, 2)
+	newHandle := func(name string) *handle {
+		h := &handle{name, freed}
+		setLeakFinalizer(h, name+" leaked", func(h *handle) { h.freed <- h.name })
+		return h
+	}
+	runtime.SetFinalizer(newHandle("released"), nil)
+	newHandle("lost")
+
+	timeout := time.After(5 * time.Second)
+	for len(freed) == 0 {
+		runtime.GC()
+		select {
+		case <-timeout:
+			t.Fatal("the lost handle was not finalized")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	runtime.GC()
+	time.Sleep(10 * time.Millisecond)
+	if leak := <-leaks; leak != "lost leaked" || len(leaks) != 0 || <-freed != "lost" {
+		t.Errorf("got the leak %q, want only the lost handle reported and freed", leak)
+	}
+}
@@ -0,0 +1,188 @@
+package mip
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// SOSType is the type of a special ordered set.
+type SOSType int
+
+const (
+	SOS1 SOSType = 1 // at most one variable of the set is non-zero
+	SOS2 SOSType = 2 // at most two variables of the set are non-zero, and they are consecutive in the order of the weights
+)
+
+// SOSConstraint is a special ordered set: a set of variables ordered by weights, of which only a few may be non-zero.
+// Special ordered sets are kept apart from the constraints of the Model returned by Constraints.
+type SOSConstraint struct {
+	index   int
+	sosType SOSType
+	vars    []*Variable // sorted by weight
+	weights []float64
+}
+
+// AddSOS1 adds a special ordered set of type 1: at most one of the variables is non-zero, e.g. to choose one of
+// several continuous quantities. The weights order the variables and guide the branching of the solver,
+// a nil slice means 1, 2, ... in the order of the variables.
+//
+// Backends supporting special ordered sets receive them as such. For the others, including the OR-Tools bridge
+// as MPSolver only accepts them in direct proto solves, they are reformulated with a binary variable allowing each
+// variable to be non-zero and rows bounding the variables: their bounds must then be finite, and should be tight.
+// The rows are rebuilt when these bounds are widened between solves.
+// It panics if the numbers of variables and weights differ. Repeated variables or weights are recorded as the Model error.
+func (m *Model) AddSOS1(vars []*Variable, weights []float64) *SOSConstraint {
+	return m.addSOS(SOS1, vars, weights)
+}
+
+// AddSOS2 adds a special ordered set of type 2: at most two of the variables are non-zero, and they are consecutive
+// in the order of the weights, e.g. to interpolate between the breakpoints of a piecewise linear function.
+// See AddSOS1 for the weights and how the set is passed to the Backend.
+func (m *Model) AddSOS2(vars []*Variable, weights []float64) *SOSConstraint {
+	return m.addSOS(SOS2, vars, weights)
+}
+
+func (m *Model) addSOS(sosType SOSType, vars []*Variable, weights []float64) *SOSConstraint {
+	m.checkNotSolving()
+	if weights == nil {
+		weights = make([]float64, len(vars))
+		for i := range weights {
+			weights[i] = float64(i + 1)
+		}
+	}
+	if len(weights) != len(vars) {
+		panic(fmt.Sprintf("Invalid special ordered set: %d variables and %d weights", len(vars), len(weights)))
+	}
+	for _, v := range vars {
+		if v.model != m {
+			panic("variable " + v.name + " does not belong to this model")
+		}
+	}
+
+	order := make([]int, len(vars))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(i, j int) int { return cmp.Compare(weights[i], weights[j]) })
+	sos := &SOSConstraint{index: len(m.sos), sosType: sosType}
+	for _, i := range order {
+		sos.vars = append(sos.vars, vars[i])
+		sos.weights = append(sos.weights, weights[i])
+	}
+	m.sos = append(m.sos, sos)
+
+	seen := make(map[*Variable]bool, len(vars))
+	for i, v := range sos.vars {
+		switch w := sos.weights[i]; {
+		case math.IsNaN(w) || math.IsInf(w, 0):
+			m.setErr(fmt.Errorf("%w: %s: the weight %v of variable %s is not finite", ErrModelInvalid, sos.label(), w, v.label()))
+		case i > 0 && w == sos.weights[i-1]:
+			m.setErr(fmt.Errorf("%w: %s: the weight %v is repeated", ErrModelInvalid, sos.label(), w))
+		case seen[v]:
+			m.setErr(fmt.Errorf("%w: %s: the variable %s is repeated", ErrModelInvalid, sos.label(), v.label()))
+		}
+		seen[v] = true
+	}
+	return sos
+}
+
+// SOSConstraints returns the special ordered sets of the Model, in creation order.
+func (m *Model) SOSConstraints() []*SOSConstraint {
+	return append([]*SOSConstraint(nil), m.sos...)
+}
+
+// label identifies the special ordered set in messages.
+func (sos *SOSConstraint) label() string { return fmt.Sprintf("SOS #%d", sos.index) }
+
+// Index returns the position of the set among the special ordered sets of its Model.
+func (sos *SOSConstraint) Index() int { return sos.index }
+
+// Type returns the type of the set, SOS1 or SOS2.
+func (sos *SOSConstraint) Type() SOSType { return sos.sosType }
+
+// Variables returns the variables of the set, in the order of their weights.
+func (sos *SOSConstraint) Variables() []*Variable { return slices.Clone(sos.vars) }
+
+// Weights returns the weights of the variables of the set, in increasing order.
+func (sos *SOSConstraint) Weights() []float64 { return slices.Clone(sos.weights) }
+
+// String formats the set as "SOS2: x:1 y:2 z:3".
+func (sos *SOSConstraint) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "SOS%d:", sos.sosType)
+	for i, v := range sos.vars {
+		fmt.Fprintf(&sb, " %s:%s", v.label(), formatNumber(sos.weights[i]))
+	}
+	return sb.String()
+}
+
+// violation describes how the values violate the set, "" if they satisfy it.
+func (sos *SOSConstraint) violation(values map[*Variable]float64) string {
+	var nonZero []int
+	for i, v := range sos.vars {
+		if math.Abs(values[v]) > feasibilityTolerance {
+			nonZero = append(nonZero, i)
+		}
+	}
+	switch {
+	case sos.sosType == SOS1 && len(nonZero) > 1:
+		return fmt.Sprintf("variables %s and %s are both non-zero", sos.vars[nonZero[0]].label(), sos.vars[nonZero[1]].label())
+	case sos.sosType == SOS2 && len(nonZero) > 2:
+		return fmt.Sprintf("%d variables are non-zero", len(nonZero))
+	case sos.sosType == SOS2 && len(nonZero) == 2 && nonZero[1] != nonZero[0]+1:
+		return fmt.Sprintf("the non-zero variables %s and %s are not consecutive", sos.vars[nonZero[0]].label(), sos.vars[nonZero[1]].label())
+	}
+	return ""
+}
+
+// addSOSBinaries passes a special ordered set to a backend without native support, with one binary variable per
+// variable of an SOS1, or per pair of consecutive variables of an SOS2, at most one of them being 1. A variable
+// is bounded by l*z <= x <= u*z, where z is the sum of the binaries allowing it to be non-zero.
+// It returns the rows added to the backend.
+func (s *Solver) addSOSBinaries(sos *SOSConstraint) ([]int, error) {
+	n := len(sos.vars)
+	if sos.sosType == SOS1 && n <= 1 || sos.sosType == SOS2 && n <= 2 {
+		return nil, nil // always satisfied
+	}
+	for _, v := range sos.vars {
+		if math.IsInf(v.lowerBound, 0) || math.IsInf(v.upperBound, 0) {
+			return nil, fmt.Errorf("%w: %s: the variable %s needs finite bounds for the reformulation with binary variables",
+				ErrModelInvalid, sos.label(), v.label())
+		}
+	}
+
+	binaries := make([]int, n)
+	if sos.sosType == SOS2 {
+		binaries = binaries[:n-1]
+	}
+	ones := make([]float64, len(binaries))
+	for i := range binaries {
+		binaries[i], ones[i] = s.addColumn("", 0, 1, true), 1
+	}
+	rows := []int{s.addBackendRow("", math.Inf(-1), 1, binaries, ones)}
+
+	for i, v := range sos.vars {
+		allowing := binaries[i : i+1]
+		if sos.sosType == SOS2 {
+			allowing = binaries[max(i-1, 0):min(i+1, n-1)]
+		}
+		column := s.column(v)
+		bound := func(lb, ub, b float64) {
+			vars, coeffs := []int{column}, []float64{1}
+			for _, z := range allowing {
+				vars, coeffs = append(vars, z), append(coeffs, -b)
+			}
+			rows = append(rows, s.addBackendRow("", lb, ub, vars, coeffs))
+		}
+		if v.upperBound > 0 { // x <= u*z, implied by the bounds otherwise
+			bound(math.Inf(-1), 0, v.upperBound)
+		}
+		if v.lowerBound < 0 { // x >= l*z
+			bound(0, math.Inf(1), v.lowerBound)
+		}
+	}
+	return rows, nil
+}
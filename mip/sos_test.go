@@ -0,0 +1,122 @@
+package mip
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSolveSOSWithBinaries(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 5)
+	y := m.VarInt("y", -2, 5)
+	z := m.VarInt("z", 0, 5)
+	m.AddSOS1([]*Variable{x, y}, nil)
+	sos2 := m.AddSOS2([]*Variable{z, x, y}, []float64{3, 1, 2})
+	objective := sumOf(x, z)
+	objective.AddTerm(y, 2)
+	m.SetObjective(objective, Maximize)
+
+	s, _ := newTestSolver(m)
+	result, err := s.Solve(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// x, y and z are ordered x, y, z in the SOS2: y and z are the only consecutive pair allowed to be non-zero
+	if result.ObjectiveValue != 15 || x.Value() != 0 || y.Value() != 5 || z.Value() != 5 {
+		t.Errorf("got objective %v with x = %v, y = %v, z = %v, want 15, 0, 5 and 5", result.ObjectiveValue, x.Value(), y.Value(), z.Value())
+	}
+	if sos2.String() != "SOS2: x:1 y:2 z:3" {
+		t.Errorf("got %s", sos2)
+	}
+
+	tests := []struct {
+		x, y, z float64
+		want    string
+	}{
+		{0, -2, 5, ""},
+		{1, -1, 0, "SOS #0 is violated: variables x and y are both non-zero"},
+		{1, 0, 1, "SOS #1 is violated: the non-zero variables x and z are not consecutive"},
+	}
+	for _, test := range tests {
+		err := m.CheckSolution(map[*Variable]float64{x: test.x, y: test.y, z: test.z})
+		if test.want == "" && err != nil || test.want != "" && (err == nil || err.Error() != test.want) {
+			t.Errorf("x = %v, y = %v, z = %v: got %v, want %q", test.x, test.y, test.z, err, test.want)
+		}
+	}
+}
+
+func TestSolveSOSAfterBoundChanges(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 5)
+	y := m.VarInt("y", 0, 5)
+	m.AddSOS1([]*Variable{x, y}, nil)
+	m.SetObjective(sumOf(x, y), Maximize)
+
+	s, backend := newTestSolver(m)
+	if result, err := s.Solve(0); err != nil || result.ObjectiveValue != 5 {
+		t.Fatalf("got %+v, %v, want the optimum 5", result, err)
+	}
+	rows := len(backend.rows)
+
+	// the row x <= 5 z would cut off x > 5
+	x.SetBounds(0, 15)
+	if result, err := s.Solve(0); err != nil || result.ObjectiveValue != 15 || x.Value() != 15 {
+		t.Errorf("got %+v, %v with x = %v, want the optimum 15 at x = 15", result, err, x.Value())
+	}
+	if len(backend.rows) != 2*rows || !math.IsInf(backend.rows[rows-1].lb, -1) || !math.IsInf(backend.rows[rows-1].ub, 1) {
+		t.Errorf("got rows %+v, want the first rows relaxed and new ones", backend.rows)
+	}
+
+	x.SetBounds(0, 3)
+	if result, err := s.Solve(0); err != nil || result.ObjectiveValue != 5 || len(backend.rows) != 2*rows {
+		t.Errorf("got %+v, %v with %d rows, want the optimum 5 with the same rows", result, err, len(backend.rows))
+	}
+
+	y.SetBounds(math.Inf(-1), 5)
+	if _, err := s.Solve(0); !errors.Is(err, ErrModelInvalid) || !strings.Contains(err.Error(), "variable y needs finite bounds") {
+		t.Errorf("got %v, want ErrModelInvalid for the infinite bound of y", err)
+	}
+}
+
+func TestSolveSOSNatively(t *testing.T) {
+	m := NewModel()
+	x := m.VarFloat("x", 0, math.Inf(1))
+	y := m.VarFloat("y", 0, math.Inf(1))
+	m.AddSOS2([]*Variable{x, y}, []float64{2, 1})
+
+	s, backend := newTestSolver(m)
+	backend.sets = true
+	if _, err := s.Solve(0); err != nil {
+		t.Fatal(err)
+	}
+	if len(backend.setRows) != 1 || len(backend.rows) != 0 || backend.setRows[0].vars[0] != 1 || backend.setRows[0].coeffs[1] != 2 {
+		t.Errorf("got sets %+v and rows %+v, want the set by increasing weights only", backend.setRows, backend.rows)
+	}
+}
+
+func TestSOSRoundTrip(t *testing.T) {
+	m := exportTestModel()
+	x, y, z := m.VariableByName("x"), m.VariableByName("y"), m.VariableByName("z")
+	m.AddSOS1([]*Variable{x, y}, []float64{1, 2.5})
+	m.AddSOS2([]*Variable{x, y, z}, nil)
+
+	writeLP := func(m *Model, sb *strings.Builder) error { return m.WriteLP(sb) }
+	writeMPS := func(m *Model, sb *strings.Builder) error { return m.WriteMPS(sb) }
+	if first, second := rewrite(t, m, writeLP, readLP); first != second || !strings.Contains(first, " s0: S1:: x:1 y:2.5\n") {
+		t.Errorf("got\n%s\nafter reading\n%s", second, first)
+	}
+	if first, second := rewrite(t, m, writeMPS, readMPS); first != second || !strings.Contains(first, "\nSOS\n") {
+		t.Errorf("got\n%s\nafter reading\n%s", second, first)
+	}
+}
+
+func TestAddSOSRecordsRepeatedVariables(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 1)
+	m.AddSOS1([]*Variable{x, x}, nil)
+	if err := m.Err(); !errors.Is(err, ErrModelInvalid) || !strings.Contains(err.Error(), "the variable x is repeated") {
+		t.Errorf("got %v, want the repeated variable", err)
+	}
+}
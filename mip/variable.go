@@ -1,28 +1,106 @@
 package mip
 
-import "C"
+import "fmt"
 
 // Variable represents a decision variable in the optimization problem.
-// This is an export alias
-type Variable = variable
+type Variable struct {
+	model      *Model
+	index      int
+	name       string
+	lowerBound float64
+	upperBound float64
+	integer    bool
+
+	// information from the most recent solution
+	value       float64
+	reducedCost float64
+	basisStatus BasisStatus
+}
+
+func (m *Model) newVariable(name string, lb, ub float64, integer bool) *Variable {
+	m.checkNotSolving()
+	v := &Variable{
+		model:      m,
+		index:      len(m.variables),
+		name:       name,
+		lowerBound: lb,
+		upperBound: ub,
+		integer:    integer,
+	}
+	m.variables = append(m.variables, v)
+	m.checkVariableBounds(v)
+	// the error is sticky: a name cannot be changed, and ambiguous names would break LP and MPS round trips
+	if name != "" {
+		if _, exists := m.variablesByName[name]; exists {
+			m.setErr(fmt.Errorf("%w: variable %s", ErrDuplicateName, name))
+		} else {
+			m.variablesByName[name] = v
+		}
+	}
+	return v
+}
 
 // VarInt creates and returns a new integer Variable
-func (s *Solver) VarInt(name string, lowerBound, upperBound int) *Variable {
-	return s.newVariable(name, float64(lowerBound), float64(upperBound), 1)
+func (m *Model) VarInt(name string, lowerBound, upperBound int) *Variable {
+	return m.newVariable(name, float64(lowerBound), float64(upperBound), true)
 }
 
 // VarFloat creates and returns a new real (in the mathematical sense) Variable
-func (s *Solver) VarFloat(name string, lowerBound, upperBound float64) *Variable {
-	return s.newVariable(name, lowerBound, upperBound, 0)
+func (m *Model) VarFloat(name string, lowerBound, upperBound float64) *Variable {
+	return m.newVariable(name, lowerBound, upperBound, false)
 }
 
 // VarBool creates and returns a new decision/binary Variable
-func (s *Solver) VarBool(name string) *Variable {
-	return s.newVariable(name, 0., 1., 1)
+func (m *Model) VarBool(name string) *Variable {
+	return m.newVariable(name, 0., 1., true)
 }
 
 // Name returns the name of the variable.
-func (v *Variable) Name() string { return v.name() }
+func (v *Variable) Name() string { return v.name }
+
+// label returns the name of the variable, or its index for anonymous variables, for messages.
+func (v *Variable) label() string {
+	if v.name != "" {
+		return v.name
+	}
+	return fmt.Sprintf("#%d", v.index)
+}
+
+// Index returns the position of the variable in its Model.
+func (v *Variable) Index() int { return v.index }
+
+// Value returns the value of the variable in the most recent solution.
+func (v *Variable) Value() float64 { return v.value }
 
-// Value returns the value of the variable in the solution after optimization.
-func (v *Variable) Value() float64 { return v.solutionValue() }
+// ReducedCost returns the reduced cost of the variable in the most recent solution.
+// Reduced costs only exist for linear programs solved by an LP solver (e.g. GLOP), it is 0 otherwise.
+func (v *Variable) ReducedCost() float64 { return v.reducedCost }
+
+// BasisStatus returns the status of the variable in the simplex basis of the most recent solution.
+// Like ReducedCost, it is only available for linear programs solved by an LP solver.
+func (v *Variable) BasisStatus() BasisStatus { return v.basisStatus }
+
+// Lower returns the lower bound of the variable.
+func (v *Variable) Lower() float64 { return v.lowerBound }
+
+// Upper returns the upper bound of the variable.
+func (v *Variable) Upper() float64 { return v.upperBound }
+
+// IsInteger reports whether the variable must take an integer value.
+func (v *Variable) IsInteger() bool { return v.integer }
+
+// SetBounds changes the bounds of the variable.
+// A Solver that already materialised the variable updates it at the next Solve, without rebuilding the model.
+// Invalid bounds are reported by Model.Err until they are corrected.
+func (v *Variable) SetBounds(lowerBound, upperBound float64) {
+	v.model.checkNotSolving()
+	v.lowerBound, v.upperBound = lowerBound, upperBound
+	v.model.checkVariableBounds(v)
+}
+
+// SetInteger changes whether the variable must take an integer value,
+// e.g. to solve the linear relaxation of the model and restore it afterward.
+func (v *Variable) SetInteger(integer bool) {
+	v.model.checkNotSolving()
+	v.integer = integer
+}
@@ -0,0 +1,58 @@
+package mip
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVariableSolution(t *testing.T) {
+	m := NewModel()
+	x := m.VarFloat("x", 0, 2)
+	y := m.VarFloat("y", 0, 2)
+	m.SetObjective(sumOf(x, y), Maximize)
+
+	s, b := newTestSolver(m)
+	b.duals = true
+	if _, err := s.Solve(0); err != nil {
+		t.Fatal(err)
+	}
+	if x.Value() != 2 || y.ReducedCost() != -1 || y.BasisStatus() != Basic {
+		t.Errorf("got value %v, reduced cost %v, basis status %v, want 2, -1 and Basic", x.Value(), y.ReducedCost(), y.BasisStatus())
+	}
+
+	b.duals = false
+	if _, err := s.Solve(0); err != nil {
+		t.Fatal(err)
+	}
+	if y.ReducedCost() != 0 || y.BasisStatus() != BasisFree {
+		t.Errorf("got reduced cost %v and basis status %v without duals", y.ReducedCost(), y.BasisStatus())
+	}
+}
+
+func TestVariableSetBoundsAndInteger(t *testing.T) {
+	m := NewModel()
+	x := m.VarInt("x", 0, 10)
+	m.SetObjective(sumOf(x), Maximize)
+	s, b := newTestSolver(m)
+	if _, err := s.Solve(0); err != nil {
+		t.Fatal(err)
+	}
+
+	x.SetBounds(2, 7)
+	x.SetInteger(false)
+	if _, err := s.Solve(0); err != nil {
+		t.Fatal(err)
+	}
+	if x.Lower() != 2 || x.Upper() != 7 || x.IsInteger() || b.columns[0].ub != 7 || b.columns[0].integer || x.Value() != 7 {
+		t.Errorf("got [%v, %v] integer %v, backend %+v, value %v", x.Lower(), x.Upper(), x.IsInteger(), b.columns[0], x.Value())
+	}
+
+	x.SetBounds(8, 7)
+	if _, err := s.Solve(0); !errors.Is(err, ErrModelInvalid) {
+		t.Errorf("got %v, want ErrModelInvalid for crossed bounds", err)
+	}
+	x.SetBounds(3, 7)
+	if _, err := s.Solve(0); err != nil || x.Value() != 7 || b.columns[0].lb != 3 {
+		t.Errorf("got %v with x = %v, want the corrected bounds solved", err, x.Value())
+	}
+}